@@ -2,12 +2,17 @@ package di
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AnasImloul/go-orchestrator/internal/logger"
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
 )
 
 // Context key for scope
@@ -28,24 +33,70 @@ func GetScopeFromContext(ctx context.Context) Scope {
 
 // DefaultContainer implements the Container interface
 type DefaultContainer struct {
-	registrations map[reflect.Type]*ServiceRegistration
-	namedServices map[string]*ServiceRegistration
-	singletons    map[reflect.Type]interface{}
-	config        ContainerConfig
-	logger        logger.Logger
-	mu            sync.RWMutex
-	disposed      bool
+	registrations  map[reflect.Type]*ServiceRegistration
+	groups         map[reflect.Type][]*ServiceRegistration
+	namedServices  map[string]*ServiceRegistration
+	singletons     map[*ServiceRegistration]interface{}
+	singletonLocks map[*ServiceRegistration]*sync.Mutex // Serialize the creation of each singleton
+	owned          []ownedInstance                      // Singletons and cleanups of the container, in creation order
+	singletonsMu   sync.Mutex
+	sequence       uint64
+	config         ContainerConfig
+	logger         logger.Logger
+	interceptors   []Interceptor
+	interceptorsMu sync.RWMutex
+	intercepting   atomic.Bool // Whether instance creation goes through the interceptors
+	mu             sync.RWMutex
+	disposed       bool
+}
+
+// ownedInstance is an instance created or registered on a container or scope, together with
+// the cleanup functions its factory registered. Instance is nil for transients, whose owner
+// only runs the cleanup functions.
+type ownedInstance struct {
+	registration *ServiceRegistration
+	instance     interface{}
+	cleanups     []func()
+}
+
+// cleanupsKey is the context key of the cleanup functions of the instance being created
+type cleanupsKey struct{}
+
+// cleanupList collects the cleanup functions registered by a factory
+type cleanupList struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+// AddCleanup registers a cleanup function for the instance being created by the factory that received ctx.
+// The container or scope owning the instance calls it when it is torn down, after disposing the instance,
+// and cleanup functions of instances created later are called first. Singletons are owned by the container,
+// scoped instances by their scope, and transients by the scope they were resolved in, or else the container.
+// It reports whether ctx belongs to a factory call.
+func AddCleanup(ctx context.Context, cleanup func()) bool {
+	list, ok := ctx.Value(cleanupsKey{}).(*cleanupList)
+	if !ok || cleanup == nil {
+		return false
+	}
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.funcs = append(list.funcs, cleanup)
+	return true
 }
 
 // NewContainer creates a new DI container
 func NewContainer(config ContainerConfig, logger logger.Logger) *DefaultContainer {
-	return &DefaultContainer{
-		registrations: make(map[reflect.Type]*ServiceRegistration),
-		namedServices: make(map[string]*ServiceRegistration),
-		singletons:    make(map[reflect.Type]interface{}),
-		config:        config,
-		logger:        logger,
+	c := &DefaultContainer{
+		registrations:  make(map[reflect.Type]*ServiceRegistration),
+		groups:         make(map[reflect.Type][]*ServiceRegistration),
+		namedServices:  make(map[string]*ServiceRegistration),
+		singletons:     make(map[*ServiceRegistration]interface{}),
+		singletonLocks: make(map[*ServiceRegistration]*sync.Mutex),
+		config:         config,
+		logger:         logger,
 	}
+	c.intercepting.Store(config.EnableInterception)
+	return c
 }
 
 // Register registers a service with the container
@@ -84,13 +135,7 @@ func (c *DefaultContainer) Register(serviceType reflect.Type, factory Factory, o
 		}
 	}
 
-	// Register by type
-	c.registrations[serviceType] = registration
-
-	// Register by name if provided
-	if opts.Name != "" {
-		c.namedServices[opts.Name] = registration
-	}
+	c.add(registration)
 
 	// Record metrics if enabled
 	if c.config.EnableMetrics && c.config.MetricsProvider != nil {
@@ -123,8 +168,8 @@ func (c *DefaultContainer) RegisterInstance(serviceType reflect.Type, instance i
 		Lifetime:    Singleton,
 	}
 
-	c.registrations[serviceType] = registration
-	c.singletons[serviceType] = instance
+	c.add(registration)
+	c.storeSingleton(registration, instance, nil)
 
 	if c.logger != nil {
 		c.logger.Debug("Service instance registered",
@@ -148,37 +193,80 @@ func (c *DefaultContainer) RegisterSingleton(serviceType reflect.Type, factory F
 
 // Resolve resolves a service from the container
 func (c *DefaultContainer) Resolve(serviceType reflect.Type) (interface{}, error) {
+	// For public API, we start with depth 0
+	return c.resolve(context.Background(), serviceType, 0)
+}
+
+// ResolveContext resolves a service as part of the resolution in ctx. Factories receive a context
+// carrying their resolution depth, so dependencies they resolve through ResolveContext are nested
+// below them in traces and count towards MaxResolutionDepth.
+func (c *DefaultContainer) ResolveContext(ctx context.Context, serviceType reflect.Type) (interface{}, error) {
+	return c.resolve(ctx, serviceType, resolutionDepth(ctx))
+}
+
+// ResolveByName resolves a service by name
+func (c *DefaultContainer) ResolveByName(name string) (interface{}, error) {
+	return c.ResolveByNameContext(context.Background(), name)
+}
+
+// ResolveByNameContext resolves a service by name as part of the resolution in ctx, like ResolveContext
+func (c *DefaultContainer) ResolveByNameContext(ctx context.Context, name string) (interface{}, error) {
+	// Instances registered on the scope of the resolution take precedence over the registrations
+	if scope, ok := GetScopeFromContext(ctx).(*DefaultScope); ok {
+		if instance, exists := scope.registeredInstance(nil, name); exists {
+			return instance, nil
+		}
+	}
+
+	// Only look up the registration under the lock, since the factory may resolve or register services
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	disposed := c.disposed
+	registration, exists := c.namedServices[name]
+	c.mu.RUnlock()
 
-	if c.disposed {
+	if disposed {
 		return nil, fmt.Errorf("container is disposed")
 	}
-
-	// Check resolution depth to prevent infinite recursion
-	if c.config.MaxResolutionDepth > 0 {
-		// For public API, we start with depth 0
-		return c.resolve(context.Background(), serviceType, 0)
+	if !exists {
+		return nil, fmt.Errorf("service with name '%s' not found", name)
 	}
 
-	return c.resolve(context.Background(), serviceType, 0)
+	return c.resolveRegistration(ctx, registration.ServiceType, registration, resolutionDepth(ctx))
 }
 
-// ResolveByName resolves a service by name
-func (c *DefaultContainer) ResolveByName(name string) (interface{}, error) {
+// ResolveAll resolves every service registered for a type, optionally restricted to a group.
+// Services are ordered by priority, highest first, and then by registration order.
+func (c *DefaultContainer) ResolveAll(ctx context.Context, serviceType reflect.Type, group string) (instances []interface{}, err error) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	disposed := c.disposed
+	registrations := c.registrationsOf(serviceType, group)
+	c.mu.RUnlock()
 
-	if c.disposed {
+	if disposed {
 		return nil, fmt.Errorf("container is disposed")
 	}
 
-	registration, exists := c.namedServices[name]
-	if !exists {
-		return nil, fmt.Errorf("service with name '%s' not found", name)
+	ctx, span := c.tracer().Start(ctx, "di.resolve_all",
+		tracing.String("di.type", serviceType.String()),
+		tracing.String("di.group", group),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	span.SetAttributes(tracing.Int("di.count", len(registrations)))
+
+	depth := resolutionDepth(ctx)
+	instances = make([]interface{}, 0, len(registrations))
+	for _, registration := range registrations {
+		instance, err := c.resolveRegistration(ctx, serviceType, registration, depth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s from group '%s': %w", serviceType.String(), registration.Options.Group, err)
+		}
+		instances = append(instances, instance)
 	}
 
-	return c.resolve(context.Background(), registration.ServiceType, 0)
+	return instances, nil
 }
 
 // TryResolve attempts to resolve a service, returns false if not found
@@ -214,6 +302,11 @@ func (c *DefaultContainer) GetRegistrations() []ServiceRegistration {
 	for _, reg := range c.registrations {
 		registrations = append(registrations, *reg)
 	}
+	for _, members := range c.groups {
+		for _, reg := range members {
+			registrations = append(registrations, *reg)
+		}
+	}
 
 	return registrations
 }
@@ -223,51 +316,324 @@ func (c *DefaultContainer) CreateScope() Scope {
 	return NewScope(c, c.logger)
 }
 
-// Dispose disposes the container and all its resources
-func (c *DefaultContainer) Dispose() error {
+// Reset discards the cached singleton instance of a service so the next resolution creates a new one.
+// If a name is given, only the named registration is reset, and otherwise only the registration by type,
+// so that other services of the same type, such as group members, keep their instances.
+// Services registered with RegisterInstance keep their instance, since there is no factory to recreate it.
+func (c *DefaultContainer) Reset(serviceType reflect.Type, name string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.disposed {
-		return nil
+		return fmt.Errorf("container is disposed")
 	}
 
-	c.disposed = true
+	var registration *ServiceRegistration
+	if name != "" {
+		registration = c.namedServices[name]
+		if registration == nil {
+			return fmt.Errorf("service with name %s is not registered", name)
+		}
+	} else {
+		registration = c.registrations[serviceType]
+		if registration == nil {
+			return fmt.Errorf("service of type %s is not registered", serviceType.String())
+		}
+	}
+
+	if registration.Factory != nil {
+		c.forgetSingleton(registration)
+	}
+
+	if c.logger != nil {
+		c.logger.Debug("Singleton instance reset",
+			"type", serviceType.String(),
+			"name", name,
+		)
+	}
+
+	return nil
+}
+
+// Unregister removes a service registration and disposes its cached singleton instance.
+// If a name is given, the named registration is removed, and the registration by type
+// only if it refers to the same registration.
+func (c *DefaultContainer) Unregister(serviceType reflect.Type, name string) error {
+	removed, err := c.unregister(serviceType, name)
+	if err != nil {
+		return err
+	}
+
+	if c.logger != nil {
+		c.logger.Debug("Service unregistered",
+			"type", serviceType.String(),
+			"name", name,
+		)
+	}
+
+	// Dispose outside the lock, since disposal runs user code
+	return c.disposeSingleton(context.Background(), removed)
+}
 
-	// Dispose all singletons that implement disposable interface
-	// Skip disposing the container itself to prevent recursive disposal
-	for serviceType, instance := range c.singletons {
-		// Skip if this is the container itself to prevent recursive disposal
-		if instance == c {
-			continue
+// unregister removes a registration from the container and returns it.
+func (c *DefaultContainer) unregister(serviceType reflect.Type, name string) (*ServiceRegistration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.disposed {
+		return nil, fmt.Errorf("container is disposed")
+	}
+
+	registration, exists := c.registrations[serviceType]
+	if name != "" {
+		named, namedExists := c.namedServices[name]
+		if !namedExists {
+			return nil, fmt.Errorf("service with name %s is not registered", name)
 		}
+		delete(c.namedServices, name)
+		if named.Options.Group != "" {
+			c.removeGroupMember(named)
+			return named, nil
+		}
+		if !exists || registration != named {
+			return named, nil
+		}
+	} else if !exists {
+		return nil, fmt.Errorf("service of type %s is not registered", serviceType.String())
+	}
 
-		if disposable, ok := instance.(Disposable); ok {
-			if err := disposable.Dispose(); err != nil {
-				if c.logger != nil {
-					c.logger.Error("Failed to dispose singleton",
-						"type", serviceType.String(),
-						"error", err.Error(),
-					)
-				}
-			}
+	delete(c.registrations, serviceType)
+	return registration, nil
+}
+
+// DisposeInstances disposes the singletons created by factories in reverse creation order and discards them,
+// so that the next resolution creates them again, and runs the cleanup functions registered by factories.
+// Registered instances are kept, since the container does not own them. The errors of all instances are joined.
+func (c *DefaultContainer) DisposeInstances(ctx context.Context) error {
+	c.singletonsMu.Lock()
+	if c.singletons == nil {
+		// The container is disposed
+		c.singletonsMu.Unlock()
+		return nil
+	}
+	var created, kept []ownedInstance
+	for _, owned := range c.owned {
+		if owned.registration.Factory != nil {
+			created = append(created, owned)
+			delete(c.singletons, owned.registration)
+		} else {
+			kept = append(kept, owned)
 		}
 	}
+	c.owned = kept
+	c.singletonsMu.Unlock()
+
+	// Dispose outside the lock, since disposal runs user code
+	err := disposeInReverse(ctx, created, c, c.logger, "singleton")
+
+	if c.logger != nil {
+		c.logger.Debug("Singleton instances disposed", "count", len(created))
+	}
+
+	return err
+}
+
+// Dispose disposes the container and all its resources.
+// Singletons are disposed and cleanup functions run in reverse creation order, and the errors of all instances are joined.
+func (c *DefaultContainer) Dispose() error {
+	c.mu.Lock()
+	if c.disposed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.disposed = true
 
 	// Clear all collections
 	c.registrations = nil
+	c.groups = nil
 	c.namedServices = nil
+	c.mu.Unlock()
+
+	c.singletonsMu.Lock()
+	owned := c.owned
 	c.singletons = nil
+	c.singletonLocks = nil
+	c.owned = nil
+	c.singletonsMu.Unlock()
+
+	// Dispose outside the locks, since disposal runs user code. Skip disposing the container
+	// itself to prevent recursive disposal.
+	err := disposeInReverse(context.Background(), owned, c, c.logger, "singleton")
 
 	if c.logger != nil {
 		c.logger.Info("Container disposed")
 	}
 
-	return nil
+	return err
 }
 
 // Private helper methods
 
+// add stores a registration by type, or in its group, and by name if it has one.
+// The caller must hold the write lock.
+func (c *DefaultContainer) add(registration *ServiceRegistration) {
+	c.sequence++
+	registration.sequence = c.sequence
+
+	if group := registration.Options.Group; group != "" {
+		// Group members are kept side by side instead of replacing each other
+		c.groups[registration.ServiceType] = append(c.groups[registration.ServiceType], registration)
+	} else {
+		c.registrations[registration.ServiceType] = registration
+	}
+
+	if registration.Name != "" {
+		c.namedServices[registration.Name] = registration
+	}
+
+	if len(registration.Options.Interceptors) > 0 {
+		c.intercepting.Store(true)
+	}
+}
+
+// removeGroupMember removes a registration from its group. The caller must hold the write lock.
+func (c *DefaultContainer) removeGroupMember(registration *ServiceRegistration) {
+	members := c.groups[registration.ServiceType]
+	for i, member := range members {
+		if member == registration {
+			members = append(members[:i:i], members[i+1:]...)
+			break
+		}
+	}
+	if len(members) == 0 {
+		delete(c.groups, registration.ServiceType)
+	} else {
+		c.groups[registration.ServiceType] = members
+	}
+}
+
+// storeSingleton caches the singleton instance of a registration, unless a concurrent resolution
+// stored one first, and returns the cached instance. The cleanup functions of a discarded instance run immediately.
+// If the container was disposed while the instance was created, it is disposed right away.
+func (c *DefaultContainer) storeSingleton(registration *ServiceRegistration, instance interface{}, cleanups []func()) (interface{}, error) {
+	c.singletonsMu.Lock()
+	disposed := c.singletons == nil
+	existing, exists := c.singletons[registration]
+	if !disposed && !exists {
+		c.singletons[registration] = instance
+		c.owned = append(c.owned, ownedInstance{registration: registration, instance: instance, cleanups: cleanups})
+	}
+	c.singletonsMu.Unlock()
+
+	switch {
+	case disposed:
+		owned := []ownedInstance{{registration: registration, instance: instance, cleanups: cleanups}}
+		if err := disposeInReverse(context.Background(), owned, c, c.logger, "singleton"); err != nil && c.logger != nil {
+			c.logger.Error("Failed to dispose singleton of disposed container", "error", err)
+		}
+		return nil, fmt.Errorf("container is disposed")
+	case exists:
+		c.runCleanups(registration, cleanups)
+		return existing, nil
+	}
+	return instance, nil
+}
+
+// storeCleanups keeps the cleanup functions of a transient instance until the container is torn down.
+// If the container was disposed while the instance was created, they run immediately.
+func (c *DefaultContainer) storeCleanups(registration *ServiceRegistration, cleanups []func()) {
+	if len(cleanups) == 0 {
+		return
+	}
+	c.singletonsMu.Lock()
+	disposed := c.singletons == nil
+	if !disposed {
+		c.owned = append(c.owned, ownedInstance{registration: registration, cleanups: cleanups})
+	}
+	c.singletonsMu.Unlock()
+
+	if disposed {
+		c.runCleanups(registration, cleanups)
+	}
+}
+
+// forgetSingleton discards the cached singleton instance of a registration without disposing it.
+// Its cleanup functions still run when the container is torn down.
+func (c *DefaultContainer) forgetSingleton(registration *ServiceRegistration) {
+	c.singletonsMu.Lock()
+	defer c.singletonsMu.Unlock()
+
+	if _, exists := c.singletons[registration]; !exists {
+		return
+	}
+	delete(c.singletons, registration)
+	for i, owned := range c.owned {
+		if owned.registration == registration && owned.instance != nil {
+			if len(owned.cleanups) > 0 {
+				c.owned[i].instance = nil
+			} else {
+				c.owned = append(c.owned[:i:i], c.owned[i+1:]...)
+			}
+			break
+		}
+	}
+}
+
+// disposeSingleton discards the cached singleton instance of a removed registration, disposes it
+// and runs its cleanup functions. Registered instances are only discarded, since the container does not own them.
+func (c *DefaultContainer) disposeSingleton(ctx context.Context, registration *ServiceRegistration) error {
+	c.singletonsMu.Lock()
+	delete(c.singletons, registration)
+	delete(c.singletonLocks, registration)
+	var removed, kept []ownedInstance
+	for _, owned := range c.owned {
+		if owned.registration == registration {
+			removed = append(removed, owned)
+		} else {
+			kept = append(kept, owned)
+		}
+	}
+	c.owned = kept
+	c.singletonsMu.Unlock()
+
+	if registration.Factory == nil {
+		return nil
+	}
+	return disposeInReverse(ctx, removed, c, c.logger, "singleton")
+}
+
+// runCleanups runs the cleanup functions of an instance in reverse order, logging the failures
+func (c *DefaultContainer) runCleanups(registration *ServiceRegistration, cleanups []func()) {
+	if err := runCleanups(registration, cleanups); err != nil && c.logger != nil {
+		c.logger.Error("Failed to clean up instance",
+			"type", registration.ServiceType.String(),
+			"error", err.Error(),
+		)
+	}
+}
+
+// registrationsOf returns the registrations of a type, restricted to a group if one is given,
+// ordered by priority, highest first, and then by registration order. The caller must hold the lock.
+func (c *DefaultContainer) registrationsOf(serviceType reflect.Type, group string) []*ServiceRegistration {
+	var registrations []*ServiceRegistration
+	if registration, exists := c.registrations[serviceType]; exists && group == "" {
+		registrations = append(registrations, registration)
+	}
+	for _, member := range c.groups[serviceType] {
+		if group == "" || member.Options.Group == group {
+			registrations = append(registrations, member)
+		}
+	}
+
+	sort.SliceStable(registrations, func(i, j int) bool {
+		if registrations[i].Options.Priority != registrations[j].Options.Priority {
+			return registrations[i].Options.Priority > registrations[j].Options.Priority
+		}
+		return registrations[i].sequence < registrations[j].sequence
+	})
+	return registrations
+}
+
 // register is the internal registration method
 func (c *DefaultContainer) register(serviceType reflect.Type, factory Factory, lifetime ServiceLifetime, opts ServiceOptions) error {
 	c.mu.Lock()
@@ -292,13 +658,7 @@ func (c *DefaultContainer) register(serviceType reflect.Type, factory Factory, l
 		}
 	}
 
-	// Register by type
-	c.registrations[serviceType] = registration
-
-	// Register by name if provided
-	if opts.Name != "" {
-		c.namedServices[opts.Name] = registration
-	}
+	c.add(registration)
 
 	// Record metrics if enabled
 	if c.config.EnableMetrics && c.config.MetricsProvider != nil {
@@ -316,8 +676,38 @@ func (c *DefaultContainer) register(serviceType reflect.Type, factory Factory, l
 	return nil
 }
 
-// resolve is the internal resolution method
+// resolve is the internal resolution method. The registration is looked up under the lock, which is
+// released before resolving it, since factories may resolve their dependencies or register services.
 func (c *DefaultContainer) resolve(ctx context.Context, serviceType reflect.Type, depth int) (interface{}, error) {
+	// Instances registered on the scope of the resolution take precedence over the registrations
+	if scope, ok := GetScopeFromContext(ctx).(*DefaultScope); ok {
+		if instance, exists := scope.registeredInstance(serviceType, ""); exists {
+			return instance, nil
+		}
+	}
+
+	c.mu.RLock()
+	disposed := c.disposed
+	registration := c.registrations[serviceType]
+	c.mu.RUnlock()
+
+	if disposed {
+		return nil, fmt.Errorf("container is disposed")
+	}
+	return c.resolveRegistration(ctx, serviceType, registration, depth)
+}
+
+// resolveRegistration resolves an instance of a registration according to its lifetime.
+// A nil registration means no service of serviceType is registered.
+func (c *DefaultContainer) resolveRegistration(ctx context.Context, serviceType reflect.Type, registration *ServiceRegistration, depth int) (instance interface{}, err error) {
+	ctx, span := c.tracer().Start(ctx, "di.resolve",
+		tracing.String("di.type", serviceType.String()),
+		tracing.Int("di.depth", depth),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	start := time.Now()
 	var success bool
 	defer func() {
@@ -332,38 +722,34 @@ func (c *DefaultContainer) resolve(ctx context.Context, serviceType reflect.Type
 		return nil, fmt.Errorf("maximum resolution depth exceeded for type %s", serviceType.String())
 	}
 
-	// Find registration
-	registration, exists := c.registrations[serviceType]
-	if !exists {
+	if registration == nil {
 		return nil, fmt.Errorf("service of type %s is not registered", serviceType.String())
 	}
 
+	span.SetAttributes(tracing.String("di.lifetime", registration.Lifetime.String()))
+	if registration.Name != "" {
+		span.SetAttributes(tracing.String("di.name", registration.Name))
+	}
+	if registration.Options.Group != "" {
+		span.SetAttributes(tracing.String("di.group", registration.Options.Group))
+	}
+
 	// Handle different lifetimes
 	switch registration.Lifetime {
-	case Singleton:
-		// Check if singleton already exists
-		if instance, exists := c.singletons[serviceType]; exists {
-			success = true
-			return instance, nil
-		}
-
-		// Create new singleton instance
-		instance, err := c.createInstance(ctx, registration, depth)
-		if err != nil {
-			return nil, err
-		}
-
-		// Store singleton
-		c.singletons[serviceType] = instance
-		success = true
-		return instance, nil
-
 	case Transient:
 		// Always create new instance for transient
-		instance, err := c.createInstance(ctx, registration, depth)
+		instance, cleanups, err := c.createInstance(ctx, registration, depth)
 		if err != nil {
 			return nil, err
 		}
+		// Transients resolved within a scope are cleaned up with the scope
+		if scope, ok := GetScopeFromContext(ctx).(*DefaultScope); ok {
+			if err := scope.storeCleanups(registration, cleanups); err != nil {
+				return nil, err
+			}
+		} else {
+			c.storeCleanups(registration, cleanups)
+		}
 		success = true
 		return instance, nil
 
@@ -381,7 +767,13 @@ func (c *DefaultContainer) resolve(ctx context.Context, serviceType reflect.Type
 			}()
 		}
 
-		instance, err := scope.Resolve(serviceType)
+		var instance interface{}
+		var err error
+		if defaultScope, ok := scope.(*DefaultScope); ok {
+			instance, err = defaultScope.resolveRegistration(ctx, registration, depth)
+		} else {
+			instance, err = scope.Resolve(serviceType)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -389,72 +781,186 @@ func (c *DefaultContainer) resolve(ctx context.Context, serviceType reflect.Type
 		return instance, nil
 
 	default:
-		// Default to singleton behavior
-		if instance, exists := c.singletons[serviceType]; exists {
+		// Singletons, and any unknown lifetime, are created once and cached
+		if instance, exists := c.cachedSingleton(registration); exists {
+			span.SetAttributes(tracing.Bool("di.cached", true))
+			success = true
+			return instance, nil
+		}
+
+		// A singleton whose factory resolves itself would wait for its own creation
+		if creatingSingleton(ctx, registration) {
+			return nil, fmt.Errorf("circular dependency detected involving type %s", serviceType.String())
+		}
+
+		// Create the instance once, while concurrent resolutions of the same singleton wait for it
+		lock := c.singletonLock(registration)
+		lock.Lock()
+		defer lock.Unlock()
+
+		if instance, exists := c.cachedSingleton(registration); exists {
+			span.SetAttributes(tracing.Bool("di.cached", true))
 			success = true
 			return instance, nil
 		}
 
-		instance, err := c.createInstance(ctx, registration, depth)
+		// Create new singleton instance
+		instance, cleanups, err := c.createInstance(withSingletonCreation(ctx, registration), registration, depth)
 		if err != nil {
 			return nil, err
 		}
 
-		c.singletons[serviceType] = instance
+		// Store singleton, keeping the instance of a concurrent resolution that finished first
+		instance, err = c.storeSingleton(registration, instance, cleanups)
+		if err != nil {
+			return nil, err
+		}
 		success = true
 		return instance, nil
 	}
 }
 
-// createInstance creates a service instance using the factory
-func (c *DefaultContainer) createInstance(ctx context.Context, registration *ServiceRegistration, depth int) (instance interface{}, err error) {
+// cachedSingleton returns the cached singleton instance of a registration
+func (c *DefaultContainer) cachedSingleton(registration *ServiceRegistration) (interface{}, bool) {
+	c.singletonsMu.Lock()
+	defer c.singletonsMu.Unlock()
+
+	instance, exists := c.singletons[registration]
+	return instance, exists
+}
+
+// singletonLock returns the mutex that serializes the creation of the singleton instance of a registration
+func (c *DefaultContainer) singletonLock(registration *ServiceRegistration) *sync.Mutex {
+	c.singletonsMu.Lock()
+	defer c.singletonsMu.Unlock()
+
+	lock, exists := c.singletonLocks[registration]
+	if !exists {
+		lock = &sync.Mutex{}
+		if c.singletonLocks != nil {
+			c.singletonLocks[registration] = lock
+		}
+	}
+	return lock
+}
+
+// singletonCreationKey is the context key of the singletons being created by the resolution
+type singletonCreationKey struct{}
+
+// singletonCreation is a singleton being created, linked to the singleton whose factory resolved it
+type singletonCreation struct {
+	registration *ServiceRegistration
+	parent       *singletonCreation
+}
+
+// withSingletonCreation returns a context recording that the singleton of a registration is being created
+func withSingletonCreation(ctx context.Context, registration *ServiceRegistration) context.Context {
+	parent, _ := ctx.Value(singletonCreationKey{}).(*singletonCreation)
+	return context.WithValue(ctx, singletonCreationKey{}, &singletonCreation{registration: registration, parent: parent})
+}
+
+// creatingSingleton reports whether the resolution in ctx is creating the singleton of a registration
+func creatingSingleton(ctx context.Context, registration *ServiceRegistration) bool {
+	creation, _ := ctx.Value(singletonCreationKey{}).(*singletonCreation)
+	for ; creation != nil; creation = creation.parent {
+		if creation.registration == registration {
+			return true
+		}
+	}
+	return false
+}
+
+// createInstance creates a service instance using the factory, and returns the cleanup functions
+// the factory registered with AddCleanup. The caller owns the instance and its cleanup functions.
+func (c *DefaultContainer) createInstance(ctx context.Context, registration *ServiceRegistration, depth int) (interface{}, []func(), error) {
+	if registration.Factory == nil {
+		return nil, nil, fmt.Errorf("no factory provided for service %s", registration.ServiceType.String())
+	}
+
+	// Dependencies resolved by the factory are one level deeper
+	list := &cleanupList{}
+	ctx = withResolutionDepth(ctx, depth+1)
+	ctx = context.WithValue(ctx, cleanupsKey{}, list)
+
+	instance, err := c.callFactory(ctx, registration)
+
+	list.mu.Lock()
+	cleanups := list.funcs
+	list.mu.Unlock()
+
+	if err != nil {
+		// A failed factory call does not hand over its resources, so release them now
+		c.runCleanups(registration, cleanups)
+		return nil, nil, err
+	}
+	return instance, cleanups, nil
+}
+
+// callFactory calls the factory of a registration with its retries and interceptors, recovering from panics
+func (c *DefaultContainer) callFactory(ctx context.Context, registration *ServiceRegistration) (instance interface{}, err error) {
 	// Add panic recovery
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("panic during instance creation for type %s: %v", registration.ServiceType.String(), r)
+			instance, err = nil, fmt.Errorf("panic during instance creation for type %s: %v", registration.ServiceType.String(), r)
 		}
 	}()
 
-	if registration.Factory == nil {
-		return nil, fmt.Errorf("no factory provided for service %s", registration.ServiceType.String())
+	create := func() (interface{}, error) {
+		// Use retry logic if configured
+		if registration.Options.RetryConfig != nil {
+			var result interface{}
+			attempts := 0
+			retryErr := RetryWithBackoff(ctx, *registration.Options.RetryConfig, func() error {
+				var err error
+				attempts++
+				result, err = registration.Factory(ctx, c)
+				return err
+			})
+			tracing.SpanFromContext(ctx).SetAttributes(tracing.Int("di.retries", attempts-1))
+
+			if retryErr != nil {
+				return nil, retryErr
+			}
+			return result, nil
+		}
+
+		// Create instance directly
+		return registration.Factory(ctx, c)
 	}
 
 	// Apply interceptors if enabled
-	if c.config.EnableInterception && len(registration.Options.Interceptors) > 0 {
-		return c.applyInterceptors(ctx, registration, depth)
+	if c.intercepting.Load() {
+		return c.applyInterceptors(ctx, registration, create)
 	}
 
-	// Use retry logic if configured
-	if registration.Options.RetryConfig != nil {
-		var result interface{}
-		retryErr := RetryWithBackoff(ctx, *registration.Options.RetryConfig, func() error {
-			var err error
-			result, err = registration.Factory(ctx, c)
-			return err
-		})
+	return create()
+}
 
-		if retryErr != nil {
-			return nil, retryErr
-		}
-		return result, nil
-	}
+// AddInterceptor adds an interceptor that applies to every service, around the service's own interceptors.
+// Interceptors added first are outermost. Adding an interceptor enables interception.
+func (c *DefaultContainer) AddInterceptor(interceptor Interceptor) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
 
-	// Create instance directly
-	return registration.Factory(ctx, c)
+	c.interceptors = append(c.interceptors, interceptor)
+	c.intercepting.Store(true)
 }
 
-// applyInterceptors applies interceptors to service creation
-func (c *DefaultContainer) applyInterceptors(ctx context.Context, registration *ServiceRegistration, depth int) (interface{}, error) {
-	interceptors := registration.Options.Interceptors
+// applyInterceptors applies the global interceptors and then the registration's interceptors
+// to service creation. The first interceptor is outermost: it runs first and sees the result last.
+func (c *DefaultContainer) applyInterceptors(ctx context.Context, registration *ServiceRegistration, create func() (interface{}, error)) (interface{}, error) {
+	c.interceptorsMu.RLock()
+	interceptors := make([]Interceptor, 0, len(c.interceptors)+len(registration.Options.Interceptors))
+	interceptors = append(interceptors, c.interceptors...)
+	c.interceptorsMu.RUnlock()
+	interceptors = append(interceptors, registration.Options.Interceptors...)
+
 	if len(interceptors) == 0 {
-		return registration.Factory(ctx, c)
+		return create()
 	}
 
 	// Create interceptor chain
-	var next func() (interface{}, error)
-	next = func() (interface{}, error) {
-		return registration.Factory(ctx, c)
-	}
+	next := create
 
 	// Apply interceptors in reverse order
 	for i := len(interceptors) - 1; i >= 0; i-- {
@@ -468,6 +974,29 @@ func (c *DefaultContainer) applyInterceptors(ctx context.Context, registration *
 	return next()
 }
 
+// tracer returns the configured tracer, or a no-op tracer if none is configured
+func (c *DefaultContainer) tracer() tracing.Tracer {
+	if c.config.Tracer == nil {
+		return tracing.NoopTracer()
+	}
+	return c.config.Tracer
+}
+
+type resolutionDepthKey struct{}
+
+// withResolutionDepth returns a context carrying the resolution depth
+func withResolutionDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, resolutionDepthKey{}, depth)
+}
+
+// resolutionDepth returns the resolution depth carried by ctx, or 0 outside of a resolution
+func resolutionDepth(ctx context.Context) int {
+	if depth, ok := ctx.Value(resolutionDepthKey{}).(int); ok {
+		return depth
+	}
+	return 0
+}
+
 // validateRegistration validates a service registration
 func (c *DefaultContainer) validateRegistration(registration ServiceRegistration) error {
 	// Check for nil factory when needed
@@ -530,6 +1059,88 @@ type Disposable interface {
 	Dispose() error
 }
 
+// ContextCloser interface for resources whose cleanup takes a context
+type ContextCloser interface {
+	Close(ctx context.Context) error
+}
+
+// disposeInstance cleans up an instance that implements Disposable, io.Closer or ContextCloser
+func disposeInstance(ctx context.Context, instance interface{}) error {
+	switch disposable := instance.(type) {
+	case Disposable:
+		return disposable.Dispose()
+	case io.Closer:
+		return disposable.Close()
+	case ContextCloser:
+		return disposable.Close(ctx)
+	}
+	return nil
+}
+
+// disposeInReverse disposes the owned instances in reverse order, so that services are disposed before
+// the dependencies they were created from, and runs the cleanup functions of each instance after disposing it.
+// An instance cached for several registrations is disposed once, and skip is never disposed.
+// The errors of all instances are joined.
+func disposeInReverse(ctx context.Context, owned []ownedInstance, skip interface{}, log logger.Logger, kind string) error {
+	var errs []error
+	disposed := make(map[interface{}]bool)
+	dispose := func(instance interface{}) bool {
+		if instance == nil || instance == skip {
+			return false
+		}
+		if reflect.TypeOf(instance).Comparable() {
+			if disposed[instance] {
+				return false
+			}
+			disposed[instance] = true
+		}
+		return true
+	}
+
+	for i := len(owned) - 1; i >= 0; i-- {
+		registration, instance := owned[i].registration, owned[i].instance
+
+		if dispose(instance) {
+			if err := disposeInstance(ctx, instance); err != nil {
+				if log != nil {
+					log.Error("Failed to dispose "+kind,
+						"type", registration.ServiceType.String(),
+						"error", err.Error(),
+					)
+				}
+				errs = append(errs, fmt.Errorf("failed to dispose %s %s: %w", kind, registration.ServiceType.String(), err))
+			}
+		}
+
+		if err := runCleanups(registration, owned[i].cleanups); err != nil {
+			if log != nil {
+				log.Error("Failed to clean up "+kind,
+					"type", registration.ServiceType.String(),
+					"error", err.Error(),
+				)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runCleanups runs cleanup functions in reverse order, recovering from panics so that every function runs
+func runCleanups(registration *ServiceRegistration, cleanups []func()) error {
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					errs = append(errs, fmt.Errorf("panic during cleanup for type %s: %v", registration.ServiceType.String(), r))
+				}
+			}()
+			cleanups[i]()
+		}()
+	}
+	return errors.Join(errs...)
+}
+
 // Generic helper functions for type-safe dependency resolution
 
 // TypeOf returns the reflect.Type for a given type T
@@ -0,0 +1,246 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// reentrantService checks the container it was created by when disposed
+type reentrantService struct {
+	container Container
+	contained bool
+}
+
+func (s *reentrantService) Dispose() error {
+	s.contained = s.container.Contains(reflect.TypeOf(s))
+	return nil
+}
+
+// withinSecond runs fn and fails the test if it doesn't return within a second
+func withinSecond(t *testing.T, name string, fn func() error) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("%s() did not return, disposal is holding the container lock", name)
+	}
+}
+
+func TestDisposalRunsWithoutContainerLock(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		dispose func(c *DefaultContainer) error
+		want    bool // Whether the service is still registered while disposed
+	}{
+		{"DisposeInstances", func(c *DefaultContainer) error { return c.DisposeInstances(context.Background()) }, true},
+		{"Dispose", func(c *DefaultContainer) error { return c.Dispose() }, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewContainer(ContainerConfig{}, nil)
+			serviceType := reflect.TypeOf(&reentrantService{})
+			err := c.RegisterSingleton(serviceType, func(ctx context.Context, container Container) (interface{}, error) {
+				return &reentrantService{container: container}, nil
+			})
+			if err != nil {
+				t.Fatalf("RegisterSingleton() = %v", err)
+			}
+			instance, err := c.Resolve(serviceType)
+			if err != nil {
+				t.Fatalf("Resolve() = %v", err)
+			}
+
+			withinSecond(t, test.name, func() error { return test.dispose(c) })
+
+			if got := instance.(*reentrantService).contained; got != test.want {
+				t.Errorf("Contains() during disposal = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+type plainService struct{}
+
+type interceptedService struct{}
+
+func TestInterceptionEnabledByRegisteredInterceptors(t *testing.T) {
+	plainType := reflect.TypeOf(&plainService{})
+	interceptedType := reflect.TypeOf(&interceptedService{})
+	factory := func(ctx context.Context, container Container) (interface{}, error) {
+		return struct{}{}, nil
+	}
+	var intercepted []string
+	interceptor := func(name string) Interceptor {
+		return InterceptorFunc(func(ctx context.Context, serviceType reflect.Type, next func() (interface{}, error)) (interface{}, error) {
+			intercepted = append(intercepted, name)
+			return next()
+		})
+	}
+
+	c := NewContainer(ContainerConfig{}, nil)
+	if err := c.Register(plainType, factory, WithLifetime(Transient)); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if c.intercepting.Load() {
+		t.Fatal("interception enabled without interceptors")
+	}
+
+	if err := c.Register(interceptedType, factory, WithLifetime(Transient), WithInterceptors(interceptor("service"))); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if _, err := c.Resolve(interceptedType); err != nil {
+		t.Fatalf("Resolve() = %v", err)
+	}
+	c.AddInterceptor(interceptor("container"))
+	if _, err := c.Resolve(plainType); err != nil {
+		t.Fatalf("Resolve() = %v", err)
+	}
+
+	want := []string{"service", "container"}
+	if !reflect.DeepEqual(intercepted, want) {
+		t.Errorf("interceptors ran as %v, want %v", intercepted, want)
+	}
+}
+
+// disposalLog records the names of disposed instances in disposal order
+type disposalLog struct{ names []string }
+
+// disposingService is disposed through Dispose() error
+type disposingService struct {
+	log *disposalLog
+	err error
+}
+
+func (s *disposingService) Dispose() error {
+	s.log.names = append(s.log.names, "dispose")
+	return s.err
+}
+
+// closingService is disposed through io.Closer
+type closingService struct {
+	log *disposalLog
+	err error
+}
+
+func (s *closingService) Close() error {
+	s.log.names = append(s.log.names, "close")
+	return s.err
+}
+
+// contextClosingService is disposed through Close(ctx) error
+type contextClosingService struct{ log *disposalLog }
+
+func (s *contextClosingService) Close(ctx context.Context) error {
+	s.log.names = append(s.log.names, "close(ctx)")
+	return nil
+}
+
+// registerDisposables registers a disposing service, a closing service depending on it and a context closing
+// service depending on the closing service, and resolves the last one so that they are created in that order
+func registerDisposables(t *testing.T, c *DefaultContainer, resolver interface {
+	Resolve(serviceType reflect.Type) (interface{}, error)
+}, lifetime ServiceLifetime, log *disposalLog, errs ...error) {
+	t.Helper()
+	errs = append(errs, nil, nil)
+	disposingType := reflect.TypeOf(&disposingService{})
+	closingType := reflect.TypeOf(&closingService{})
+	contextClosingType := reflect.TypeOf(&contextClosingService{})
+	register := func(serviceType reflect.Type, factory Factory) {
+		t.Helper()
+		if err := c.Register(serviceType, factory, WithLifetime(lifetime)); err != nil {
+			t.Fatalf("Register() = %v", err)
+		}
+	}
+	register(disposingType, func(ctx context.Context, container Container) (interface{}, error) {
+		return &disposingService{log: log, err: errs[0]}, nil
+	})
+	register(closingType, func(ctx context.Context, container Container) (interface{}, error) {
+		if _, err := container.ResolveContext(ctx, disposingType); err != nil {
+			return nil, err
+		}
+		return &closingService{log: log, err: errs[1]}, nil
+	})
+	register(contextClosingType, func(ctx context.Context, container Container) (interface{}, error) {
+		if _, err := container.ResolveContext(ctx, closingType); err != nil {
+			return nil, err
+		}
+		return &contextClosingService{log: log}, nil
+	})
+	if _, err := resolver.Resolve(contextClosingType); err != nil {
+		t.Fatalf("Resolve() = %v", err)
+	}
+}
+
+func TestDisposeInReverseCreationOrder(t *testing.T) {
+	want := []string{"close(ctx)", "close", "dispose"}
+
+	t.Run("Container", func(t *testing.T) {
+		log := &disposalLog{}
+		c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+		registerDisposables(t, c, c, Singleton, log)
+		if err := c.Dispose(); err != nil {
+			t.Fatalf("Dispose() = %v", err)
+		}
+		if !reflect.DeepEqual(log.names, want) {
+			t.Errorf("disposed as %v, want %v", log.names, want)
+		}
+		if err := c.Dispose(); err != nil || len(log.names) != len(want) {
+			t.Errorf("second Dispose() = %v and disposed %v, want a no-op", err, log.names)
+		}
+	})
+
+	t.Run("Scope", func(t *testing.T) {
+		log := &disposalLog{}
+		c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+		scope := NewScope(c, nil)
+		registerDisposables(t, c, scope, Scoped, log)
+		if err := scope.Dispose(); err != nil {
+			t.Fatalf("Dispose() = %v", err)
+		}
+		if !reflect.DeepEqual(log.names, want) {
+			t.Errorf("disposed as %v, want %v", log.names, want)
+		}
+	})
+}
+
+func TestDisposeJoinsErrors(t *testing.T) {
+	log := &disposalLog{}
+	c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+	registerDisposables(t, c, c, Singleton, log, io.ErrClosedPipe, io.ErrUnexpectedEOF)
+
+	err := c.DisposeInstances(context.Background())
+
+	if !errors.Is(err, io.ErrClosedPipe) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("DisposeInstances() = %v, want the errors of both failing instances", err)
+	}
+	if len(log.names) != 3 {
+		t.Errorf("disposed %v, want every instance disposed despite the errors", log.names)
+	}
+}
+
+func TestDisposeInstancesKeepsRegisteredInstances(t *testing.T) {
+	log := &disposalLog{}
+	c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+	serviceType := reflect.TypeOf(&closingService{})
+	registered := &closingService{log: log}
+	if err := c.RegisterInstance(serviceType, registered); err != nil {
+		t.Fatalf("RegisterInstance() = %v", err)
+	}
+	if err := c.DisposeInstances(context.Background()); err != nil {
+		t.Fatalf("DisposeInstances() = %v", err)
+	}
+	if len(log.names) != 0 {
+		t.Errorf("disposed %v, want registered instances left to their owner", log.names)
+	}
+	if instance, err := c.Resolve(serviceType); err != nil || instance != registered {
+		t.Errorf("Resolve() = %v, %v after DisposeInstances, want the registered instance", instance, err)
+	}
+}
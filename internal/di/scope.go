@@ -2,6 +2,7 @@ package di
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -12,7 +13,12 @@ import (
 // DefaultScope implements the Scope interface
 type DefaultScope struct {
 	container       *DefaultContainer
-	scopedInstances map[reflect.Type]interface{}
+	parent          *DefaultScope
+	children        []*DefaultScope
+	scopedInstances map[*ServiceRegistration]interface{}
+	owned           []ownedInstance // Scoped instances and cleanups of the scope, in creation order
+	instances       map[reflect.Type]*ServiceRegistration
+	namedInstances  map[string]*ServiceRegistration
 	logger          logger.Logger
 	mu              sync.RWMutex
 	disposed        bool
@@ -22,120 +28,325 @@ type DefaultScope struct {
 func NewScope(container *DefaultContainer, logger logger.Logger) *DefaultScope {
 	return &DefaultScope{
 		container:       container,
-		scopedInstances: make(map[reflect.Type]interface{}),
+		scopedInstances: make(map[*ServiceRegistration]interface{}),
+		instances:       make(map[reflect.Type]*ServiceRegistration),
+		namedInstances:  make(map[string]*ServiceRegistration),
 		logger:          logger,
 	}
 }
 
-// Resolve resolves a service within this scope
-func (s *DefaultScope) Resolve(serviceType reflect.Type) (interface{}, error) {
+// CreateChild creates a child scope that inherits the scoped instances and registered instances of this scope.
+// Instances registered on the child override those of this scope, and scoped instances that this scope has
+// not created yet are created and owned by the child. Disposing this scope disposes its children first.
+func (s *DefaultScope) CreateChild() Scope {
+	child := NewScope(s.container, s.logger)
+	child.parent = s
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	if s.disposed {
-		return nil, fmt.Errorf("scope is disposed")
-	}
-
-	// Check if we have a scoped instance
-	if instance, exists := s.scopedInstances[serviceType]; exists {
-		return instance, nil
+	if !s.disposed {
+		s.children = append(s.children, child)
 	}
+	return child
+}
 
-	// Get registration from container with proper locking
-	s.container.mu.RLock()
-	registration, exists := s.container.registrations[serviceType]
-	s.container.mu.RUnlock()
+// RegisterInstance registers an instance that is only resolvable within this scope and its children,
+// such as the current request or tenant. It takes precedence over the registrations of the container.
+// The scope does not own the instance, so it is not disposed with the scope.
+func (s *DefaultScope) RegisterInstance(serviceType reflect.Type, instance interface{}) error {
+	return s.registerInstance("", serviceType, instance)
+}
 
-	if !exists {
-		return nil, fmt.Errorf("service of type %s is not registered", serviceType.String())
+// RegisterNamedInstance registers a named instance that is only resolvable within this scope and its children
+func (s *DefaultScope) RegisterNamedInstance(name string, serviceType reflect.Type, instance interface{}) error {
+	if name == "" {
+		return fmt.Errorf("service name cannot be empty")
 	}
+	return s.registerInstance(name, serviceType, instance)
+}
 
-	// Handle different lifetimes
-	switch registration.Lifetime {
-	case Singleton:
-		// Singletons are resolved from the container
-		return s.container.resolve(context.Background(), serviceType, 0)
+// Contains reports whether a service is registered on this scope, one of its parents or the container
+func (s *DefaultScope) Contains(serviceType reflect.Type) bool {
+	if _, exists := s.registeredInstance(serviceType, ""); exists {
+		return true
+	}
+	return s.container.Contains(serviceType)
+}
 
-	case Scoped:
-		// Create scoped instance
-		instance, err := s.createScopedInstance(registration)
-		if err != nil {
-			return nil, err
-		}
-		s.scopedInstances[serviceType] = instance
-		return instance, nil
+// ContainsByName reports whether a named service is registered on this scope, one of its parents or the container
+func (s *DefaultScope) ContainsByName(name string) bool {
+	if _, exists := s.registeredInstance(nil, name); exists {
+		return true
+	}
+	return s.container.ContainsByName(name)
+}
 
-	case Transient:
-		// Transients are always created new
-		return s.createTransientInstance(registration)
+// Resolve resolves a service within this scope
+func (s *DefaultScope) Resolve(serviceType reflect.Type) (interface{}, error) {
+	return s.ResolveContext(context.Background(), serviceType)
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported service lifetime: %v", registration.Lifetime)
+// ResolveContext resolves a service within this scope as part of the resolution or trace in ctx
+func (s *DefaultScope) ResolveContext(ctx context.Context, serviceType reflect.Type) (interface{}, error) {
+	if err := s.checkDisposed(); err != nil {
+		return nil, err
 	}
+	return s.container.ResolveContext(WithScope(ctx, s), serviceType)
 }
 
 // ResolveByName resolves a service by name within this scope
 func (s *DefaultScope) ResolveByName(name string) (interface{}, error) {
-	// Get registration from container with proper locking
-	s.container.mu.RLock()
-	registration, exists := s.container.namedServices[name]
-	s.container.mu.RUnlock()
+	return s.ResolveByNameContext(context.Background(), name)
+}
 
-	if !exists {
-		return nil, fmt.Errorf("service with name '%s' not found", name)
+// ResolveByNameContext resolves a service by name within this scope as part of the resolution or trace in ctx
+func (s *DefaultScope) ResolveByNameContext(ctx context.Context, name string) (interface{}, error) {
+	if err := s.checkDisposed(); err != nil {
+		return nil, err
 	}
+	return s.container.ResolveByNameContext(WithScope(ctx, s), name)
+}
+
+// ResolveAll resolves every service registered for a type within this scope, optionally restricted to a group
+func (s *DefaultScope) ResolveAll(serviceType reflect.Type, group string) ([]interface{}, error) {
+	return s.ResolveAllContext(context.Background(), serviceType, group)
+}
 
-	return s.Resolve(registration.ServiceType)
+// ResolveAllContext is ResolveAll as part of the resolution or trace in ctx
+func (s *DefaultScope) ResolveAllContext(ctx context.Context, serviceType reflect.Type, group string) ([]interface{}, error) {
+	if err := s.checkDisposed(); err != nil {
+		return nil, err
+	}
+	return s.container.ResolveAll(WithScope(ctx, s), serviceType, group)
 }
 
-// Dispose disposes the scope and all scoped instances
+// Dispose disposes the scope and all scoped instances.
+// Child scopes are disposed first, then instances in reverse creation order together with the cleanup functions
+// of the scoped and transient instances created in the scope, and the errors of all instances are joined.
 func (s *DefaultScope) Dispose() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if s.disposed {
+		s.mu.Unlock()
 		return nil
 	}
-
 	s.disposed = true
-
-	// Dispose all scoped instances that implement disposable interface
-	for serviceType, instance := range s.scopedInstances {
-		if disposable, ok := instance.(Disposable); ok {
-			if err := disposable.Dispose(); err != nil {
-				if s.logger != nil {
-					s.logger.Error("Failed to dispose scoped instance",
-						"type", serviceType.String(),
-						"error", err.Error(),
-					)
-				}
-			}
+	children := s.children
+	s.children = nil
+	s.mu.Unlock()
+
+	// Dispose child scopes first, since their instances may depend on the instances of this scope
+	var errs []error
+	for i := len(children) - 1; i >= 0; i-- {
+		if err := children[i].Dispose(); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
+	s.mu.Lock()
+	errs = append(errs, disposeInReverse(context.Background(), s.owned, nil, s.logger, "scoped instance"))
+
 	// Clear scoped instances
 	s.scopedInstances = nil
+	s.owned = nil
+	s.instances = nil
+	s.namedInstances = nil
+	s.mu.Unlock()
+
+	if s.parent != nil {
+		s.parent.removeChild(s)
+	}
 
 	if s.logger != nil {
 		s.logger.Debug("Scope disposed")
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // Private helper methods
 
+// checkDisposed returns an error if the scope is disposed
+func (s *DefaultScope) checkDisposed() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.disposed {
+		return fmt.Errorf("scope is disposed")
+	}
+	return nil
+}
+
+// registerInstance registers an instance on this scope, by name if one is given
+func (s *DefaultScope) registerInstance(name string, serviceType reflect.Type, instance interface{}) error {
+	if serviceType == nil {
+		return fmt.Errorf("service type cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disposed {
+		return fmt.Errorf("scope is disposed")
+	}
+
+	registration := &ServiceRegistration{
+		ServiceType: serviceType,
+		Name:        name,
+		Instance:    instance,
+		Lifetime:    Scoped,
+	}
+	if name != "" {
+		s.namedInstances[name] = registration
+	} else {
+		s.instances[serviceType] = registration
+	}
+
+	if s.logger != nil {
+		s.logger.Debug("Scope instance registered",
+			"type", serviceType.String(),
+			"name", name,
+		)
+	}
+
+	return nil
+}
+
+// registeredInstance returns the instance registered on this scope or the nearest parent that has one,
+// looked up by name if one is given
+func (s *DefaultScope) registeredInstance(serviceType reflect.Type, name string) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		scope.mu.RLock()
+		var registration *ServiceRegistration
+		if name != "" {
+			registration = scope.namedInstances[name]
+		} else {
+			registration = scope.instances[serviceType]
+		}
+		scope.mu.RUnlock()
+
+		if registration != nil {
+			return registration.Instance, true
+		}
+	}
+	return nil, false
+}
+
+// scopedInstance returns the instance of a registration created by this scope or the nearest parent that has one
+func (s *DefaultScope) scopedInstance(registration *ServiceRegistration) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		scope.mu.RLock()
+		instance, exists := scope.scopedInstances[registration]
+		scope.mu.RUnlock()
+
+		if exists {
+			return instance, true
+		}
+	}
+	return nil, false
+}
+
+// removeChild forgets a disposed child scope
+func (s *DefaultScope) removeChild(child *DefaultScope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.children {
+		if existing == child {
+			s.children = append(s.children[:i:i], s.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// resolveRegistration resolves an instance of a registration within this scope.
+// Scoped and transient instances are created with the scope in their context, so that the
+// dependencies they resolve through the context come from the same scope.
+func (s *DefaultScope) resolveRegistration(ctx context.Context, registration *ServiceRegistration, depth int) (interface{}, error) {
+	if err := s.checkDisposed(); err != nil {
+		return nil, err
+	}
+
+	// Check if we or a parent have a scoped instance
+	if instance, exists := s.scopedInstance(registration); exists {
+		return instance, nil
+	}
+
+	// Handle different lifetimes
+	switch registration.Lifetime {
+	case Singleton:
+		// Singletons are resolved from the container
+		return s.container.resolveRegistration(ctx, registration.ServiceType, registration, depth)
+
+	case Scoped:
+		// Create scoped instance without holding the lock, since its dependencies may be scoped as well
+		instance, cleanups, err := s.createScopedInstance(WithScope(ctx, s), registration, depth)
+		if err != nil {
+			return nil, err
+		}
+		return s.store(registration, instance, cleanups)
+
+	case Transient:
+		// Transients are always created new, and only their cleanup functions are kept
+		instance, cleanups, err := s.createTransientInstance(WithScope(ctx, s), registration, depth)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.storeCleanups(registration, cleanups); err != nil {
+			return nil, err
+		}
+		return instance, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported service lifetime: %v", registration.Lifetime)
+	}
+}
+
+// store keeps an instance created in this scope together with its cleanup functions, and returns the instance
+// of the registration. A nil instance only keeps the cleanup functions of a transient. If the scope was disposed
+// or a concurrent resolution stored a scoped instance first, the cleanup functions run immediately.
+func (s *DefaultScope) store(registration *ServiceRegistration, instance interface{}, cleanups []func()) (interface{}, error) {
+	s.mu.Lock()
+	disposed := s.disposed
+	existing, exists := s.scopedInstances[registration]
+	if !disposed && (instance == nil || !exists) {
+		if instance != nil {
+			s.scopedInstances[registration] = instance
+		}
+		s.owned = append(s.owned, ownedInstance{registration: registration, instance: instance, cleanups: cleanups})
+	}
+	s.mu.Unlock()
+
+	switch {
+	case disposed:
+		s.container.runCleanups(registration, cleanups)
+		return nil, fmt.Errorf("scope is disposed")
+	case instance != nil && exists:
+		// Keep the instance of a concurrent resolution that finished first
+		s.container.runCleanups(registration, cleanups)
+		return existing, nil
+	}
+	return instance, nil
+}
+
+// storeCleanups keeps the cleanup functions of a transient instance created in this scope until it is disposed
+func (s *DefaultScope) storeCleanups(registration *ServiceRegistration, cleanups []func()) error {
+	if len(cleanups) == 0 {
+		return nil
+	}
+	_, err := s.store(registration, nil, cleanups)
+	return err
+}
+
 // createScopedInstance creates a scoped service instance
-func (s *DefaultScope) createScopedInstance(registration *ServiceRegistration) (interface{}, error) {
+func (s *DefaultScope) createScopedInstance(ctx context.Context, registration *ServiceRegistration, depth int) (interface{}, []func(), error) {
 	if registration.Factory == nil {
-		return nil, fmt.Errorf("no factory provided for scoped service %s", registration.ServiceType.String())
+		return nil, nil, fmt.Errorf("no factory provided for scoped service %s", registration.ServiceType.String())
 	}
 
-	// Create instance using factory with scope context
-	ctx := context.Background()
-	instance, err := registration.Factory(ctx, s.container)
+	// Create instance through the container so that retries and interceptors apply
+	instance, cleanups, err := s.container.createInstance(ctx, registration, depth)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if s.logger != nil {
@@ -144,20 +355,19 @@ func (s *DefaultScope) createScopedInstance(registration *ServiceRegistration) (
 		)
 	}
 
-	return instance, nil
+	return instance, cleanups, nil
 }
 
 // createTransientInstance creates a transient service instance
-func (s *DefaultScope) createTransientInstance(registration *ServiceRegistration) (interface{}, error) {
+func (s *DefaultScope) createTransientInstance(ctx context.Context, registration *ServiceRegistration, depth int) (interface{}, []func(), error) {
 	if registration.Factory == nil {
-		return nil, fmt.Errorf("no factory provided for transient service %s", registration.ServiceType.String())
+		return nil, nil, fmt.Errorf("no factory provided for transient service %s", registration.ServiceType.String())
 	}
 
-	// Create instance using factory
-	ctx := context.Background()
-	instance, err := registration.Factory(ctx, s.container)
+	// Create instance through the container so that retries and interceptors apply
+	instance, cleanups, err := s.container.createInstance(ctx, registration, depth)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if s.logger != nil {
@@ -166,5 +376,5 @@ func (s *DefaultScope) createTransientInstance(registration *ServiceRegistration
 		)
 	}
 
-	return instance, nil
+	return instance, cleanups, nil
 }
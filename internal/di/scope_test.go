@@ -0,0 +1,153 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// tenant is registered on scopes as the tenant of the current request
+type tenant struct{ name string }
+
+// tenantRepository is a scoped service created for the tenant of its scope
+type tenantRepository struct{ tenant *tenant }
+
+var (
+	tenantType     = reflect.TypeOf(&tenant{})
+	repositoryType = reflect.TypeOf(&tenantRepository{})
+)
+
+// newTenantContainer registers a scoped repository injecting the tenant registered on the scope
+func newTenantContainer(t *testing.T) *DefaultContainer {
+	t.Helper()
+	c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+	err := c.Register(repositoryType, func(ctx context.Context, container Container) (interface{}, error) {
+		instance, err := container.ResolveContext(ctx, tenantType)
+		if err != nil {
+			return nil, err
+		}
+		return &tenantRepository{tenant: instance.(*tenant)}, nil
+	}, WithLifetime(Scoped))
+	if err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	return c
+}
+
+// resolveIn resolves a service type within a scope and fails the test on error
+func resolveIn(t *testing.T, scope Scope, serviceType reflect.Type) interface{} {
+	t.Helper()
+	instance, err := scope.Resolve(serviceType)
+	if err != nil {
+		t.Fatalf("Resolve(%s) = %v", serviceType, err)
+	}
+	return instance
+}
+
+func TestNamedScopedRegistrationsOfSameType(t *testing.T) {
+	c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+	for _, name := range []string{"primary", "replica"} {
+		err := c.Register(tenantType, func(ctx context.Context, container Container) (interface{}, error) {
+			return &tenant{name: name}, nil
+		}, WithName(name), WithLifetime(Scoped))
+		if err != nil {
+			t.Fatalf("Register() = %v", err)
+		}
+	}
+	scope := NewScope(c, nil)
+	defer scope.Dispose()
+
+	resolve := func(name string) *tenant {
+		t.Helper()
+		instance, err := scope.ResolveByName(name)
+		if err != nil {
+			t.Fatalf("ResolveByName(%q) = %v", name, err)
+		}
+		return instance.(*tenant)
+	}
+	primary, replica := resolve("primary"), resolve("replica")
+
+	if primary.name != "primary" || replica.name != "replica" {
+		t.Errorf("resolved %q and %q, want each registration's own instance", primary.name, replica.name)
+	}
+	if resolve("primary") != primary {
+		t.Error("named scoped instance was created again within its scope")
+	}
+}
+
+func TestChildScopeInheritsScopedInstances(t *testing.T) {
+	c := newTenantContainer(t)
+	parent := NewScope(c, nil)
+	defer parent.Dispose()
+	if err := parent.RegisterInstance(tenantType, &tenant{name: "acme"}); err != nil {
+		t.Fatalf("RegisterInstance() = %v", err)
+	}
+	repository := resolveIn(t, parent, repositoryType)
+
+	child := parent.CreateChild()
+	if resolveIn(t, child, repositoryType) != repository {
+		t.Error("child scope created its own instance of a scoped service its parent already created")
+	}
+	if got := resolveIn(t, child.CreateChild(), tenantType).(*tenant); got.name != "acme" {
+		t.Errorf("grandchild resolved tenant %q, want the instance registered on the parent", got.name)
+	}
+}
+
+func TestChildScopeOverridesRegisteredInstances(t *testing.T) {
+	c := newTenantContainer(t)
+	parent := NewScope(c, nil)
+	defer parent.Dispose()
+	if err := parent.RegisterInstance(tenantType, &tenant{name: "acme"}); err != nil {
+		t.Fatalf("RegisterInstance() = %v", err)
+	}
+	child := parent.CreateChild()
+	if err := child.RegisterInstance(tenantType, &tenant{name: "globex"}); err != nil {
+		t.Fatalf("RegisterInstance() = %v", err)
+	}
+
+	// The child creates the scoped repository the parent has not created yet, so it is owned by the child
+	childRepository := resolveIn(t, child, repositoryType).(*tenantRepository)
+	parentRepository := resolveIn(t, parent, repositoryType).(*tenantRepository)
+
+	if childRepository.tenant.name != "globex" || parentRepository.tenant.name != "acme" {
+		t.Errorf("repositories are for %q and %q, want each scope's own tenant", childRepository.tenant.name, parentRepository.tenant.name)
+	}
+	if err := child.Dispose(); err != nil {
+		t.Fatalf("Dispose() = %v", err)
+	}
+	if resolveIn(t, parent, repositoryType) != parentRepository {
+		t.Error("disposing the child discarded the instance of its parent")
+	}
+}
+
+func TestScopeDisposesChildrenFirst(t *testing.T) {
+	log := &disposalLog{}
+	c := NewContainer(ContainerConfig{MaxResolutionDepth: 10}, nil)
+	register := func(serviceType reflect.Type, instance interface{}) {
+		t.Helper()
+		err := c.Register(serviceType, func(ctx context.Context, container Container) (interface{}, error) {
+			return instance, nil
+		}, WithLifetime(Scoped))
+		if err != nil {
+			t.Fatalf("Register() = %v", err)
+		}
+	}
+	register(reflect.TypeOf(&disposingService{}), &disposingService{log: log})
+	register(reflect.TypeOf(&closingService{}), &closingService{log: log})
+
+	parent := NewScope(c, nil)
+	resolveIn(t, parent, reflect.TypeOf(&disposingService{}))
+	child := parent.CreateChild()
+	resolveIn(t, child, reflect.TypeOf(&closingService{}))
+
+	if err := parent.Dispose(); err != nil {
+		t.Fatalf("Dispose() = %v", err)
+	}
+
+	if want := []string{"close", "dispose"}; !reflect.DeepEqual(log.names, want) {
+		t.Errorf("disposed as %v, want the child's instance disposed first", log.names)
+	}
+	if _, err := child.Resolve(reflect.TypeOf(&closingService{})); err == nil {
+		t.Error("child of a disposed scope still resolves")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"reflect"
 	"time"
+
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
 )
 
 // Container represents a dependency injection container
@@ -20,9 +22,19 @@ type Container interface {
 	// Resolve resolves a service from the container
 	Resolve(serviceType reflect.Type) (interface{}, error)
 
+	// ResolveContext resolves a service from the container as part of the resolution or trace in ctx
+	ResolveContext(ctx context.Context, serviceType reflect.Type) (interface{}, error)
+
 	// ResolveByName resolves a service by name
 	ResolveByName(name string) (interface{}, error)
 
+	// ResolveByNameContext resolves a service by name as part of the resolution or trace in ctx
+	ResolveByNameContext(ctx context.Context, name string) (interface{}, error)
+
+	// ResolveAll resolves every service registered for a type, optionally restricted to a group.
+	// Group members come first, ordered by priority and then registration order.
+	ResolveAll(ctx context.Context, serviceType reflect.Type, group string) ([]interface{}, error)
+
 	// TryResolve attempts to resolve a service, returns false if not found
 	TryResolve(serviceType reflect.Type) (interface{}, bool)
 
@@ -38,6 +50,19 @@ type Container interface {
 	// CreateScope creates a new scope
 	CreateScope() Scope
 
+	// Reset discards the cached singleton instance of a service so the next resolution creates a new one,
+	// looked up by name if one is given
+	Reset(serviceType reflect.Type, name string) error
+
+	// Unregister removes a service registration, looked up by name if one is given
+	Unregister(serviceType reflect.Type, name string) error
+
+	// AddInterceptor adds an interceptor that applies to every service
+	AddInterceptor(interceptor Interceptor)
+
+	// DisposeInstances disposes the singletons created by factories in reverse creation order and discards them
+	DisposeInstances(ctx context.Context) error
+
 	// Dispose disposes the container and all its resources
 	Dispose() error
 }
@@ -47,9 +72,36 @@ type Scope interface {
 	// Resolve resolves a service within this scope
 	Resolve(serviceType reflect.Type) (interface{}, error)
 
+	// ResolveContext resolves a service within this scope as part of the resolution or trace in ctx
+	ResolveContext(ctx context.Context, serviceType reflect.Type) (interface{}, error)
+
 	// ResolveByName resolves a service by name within this scope
 	ResolveByName(name string) (interface{}, error)
 
+	// ResolveByNameContext resolves a service by name within this scope as part of the resolution or trace in ctx
+	ResolveByNameContext(ctx context.Context, name string) (interface{}, error)
+
+	// ResolveAll resolves every service registered for a type within this scope, optionally restricted to a group
+	ResolveAll(serviceType reflect.Type, group string) ([]interface{}, error)
+
+	// ResolveAllContext resolves every service registered for a type within this scope as part of the resolution in ctx
+	ResolveAllContext(ctx context.Context, serviceType reflect.Type, group string) ([]interface{}, error)
+
+	// Contains checks if a service is registered on the scope, one of its parents or the container
+	Contains(serviceType reflect.Type) bool
+
+	// ContainsByName checks if a named service is registered on the scope, one of its parents or the container
+	ContainsByName(name string) bool
+
+	// RegisterInstance registers an instance that is only resolvable within the scope and its children
+	RegisterInstance(serviceType reflect.Type, instance interface{}) error
+
+	// RegisterNamedInstance registers a named instance that is only resolvable within the scope and its children
+	RegisterNamedInstance(name string, serviceType reflect.Type, instance interface{}) error
+
+	// CreateChild creates a child scope that inherits the instances of the scope
+	CreateChild() Scope
+
 	// Dispose disposes the scope and all scoped instances
 	Dispose() error
 }
@@ -71,6 +123,20 @@ const (
 	Scoped
 )
 
+// String returns the string representation of the service lifetime
+func (l ServiceLifetime) String() string {
+	switch l {
+	case Transient:
+		return "transient"
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	default:
+		return "unknown"
+	}
+}
+
 // RetryConfig configures retry behavior for service operations
 type RetryConfig struct {
 	MaxAttempts       int           // Maximum number of retry attempts (default: 3)
@@ -88,6 +154,7 @@ type ServiceRegistration struct {
 	Instance    interface{}
 	Lifetime    ServiceLifetime
 	Options     ServiceOptions
+	sequence    uint64 // Registration order, used to order group members
 }
 
 // ServiceOptions holds options for service registration
@@ -96,6 +163,8 @@ type ServiceOptions struct {
 	Tags         []string
 	Dependencies []reflect.Type
 	Interceptors []Interceptor
+	Group        string // Group the service is a member of; members never replace each other
+	Priority     int    // Position within the group; higher priorities resolve first
 	Metadata     map[string]interface{}
 	RetryConfig  *RetryConfig
 	Lifetime     ServiceLifetime
@@ -141,6 +210,21 @@ func WithInterceptors(interceptors ...Interceptor) Option {
 	}
 }
 
+// WithGroup makes the service a member of a group. Several services of the same type can be
+// registered in a group without replacing each other, and are resolved together with ResolveAll.
+func WithGroup(group string) Option {
+	return func(o *ServiceOptions) {
+		o.Group = group
+	}
+}
+
+// WithPriority sets the position of the service within its group; higher priorities resolve first
+func WithPriority(priority int) Option {
+	return func(o *ServiceOptions) {
+		o.Priority = priority
+	}
+}
+
 // WithMetadata adds metadata to the service registration
 func WithMetadata(key string, value interface{}) Option {
 	return func(o *ServiceOptions) {
@@ -222,11 +306,12 @@ type ContainerBuilder interface {
 type ContainerConfig struct {
 	EnableValidation    bool
 	EnableCircularCheck bool
-	EnableInterception  bool
+	EnableInterception  bool // Also enabled once an interceptor is added to the container or a registration
 	DefaultLifetime     ServiceLifetime
 	MaxResolutionDepth  int
 	EnableMetrics       bool
 	MetricsProvider     MetricsProvider
+	Tracer              tracing.Tracer // Creates a span per resolution; no-op if nil
 }
 
 // MetricsProvider provides metrics for DI operations
@@ -159,6 +159,23 @@ func (d *DAG) GetShutdownOrder() ([]*Node, error) {
 	return shutdownOrder, nil
 }
 
+// GetShutdownLevels returns components grouped by dependency level in reverse order for parallel shutdown.
+// Every component in a level only has dependents in earlier levels, so a level can be stopped
+// concurrently once all previous levels have stopped.
+func (d *DAG) GetShutdownLevels() ([][]*Node, error) {
+	startupLevels, err := d.GetStartupLevels()
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownLevels := make([][]*Node, len(startupLevels))
+	for i, level := range startupLevels {
+		shutdownLevels[len(startupLevels)-1-i] = level
+	}
+
+	return shutdownLevels, nil
+}
+
 // GetNode returns a node by name
 func (d *DAG) GetNode(name string) (*Node, bool) {
 	node, exists := d.nodes[name]
@@ -0,0 +1,84 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LifecycleError reports every component that failed during a lifecycle phase.
+// It supports errors.Is and errors.As over all component errors, like errors.Join.
+type LifecycleError struct {
+	Phase    Phase
+	Level    int              // DAG level at which the components failed, -1 when failures span several levels
+	Errors   map[string]error // Component errors keyed by component name
+	Rollback *RollbackReport  // Components stopped while rolling back a failed startup (nil for other phases)
+}
+
+// RollbackReport describes the components stopped while rolling back a failed startup
+type RollbackReport struct {
+	Entries []RollbackEntry // In stop order: dependents before their dependencies
+}
+
+// RollbackEntry describes the rollback of a single component
+type RollbackEntry struct {
+	Component string
+	Level     int
+	Stopped   bool
+	Error     error
+}
+
+// Failed returns the entries of components that could not be stopped
+func (r *RollbackReport) Failed() []RollbackEntry {
+	var failed []RollbackEntry
+	for _, entry := range r.Entries {
+		if !entry.Stopped {
+			failed = append(failed, entry)
+		}
+	}
+	return failed
+}
+
+// Error implements the error interface
+func (e *LifecycleError) Error() string {
+	components := e.Components()
+
+	parts := make([]string, 0, len(components))
+	for _, name := range components {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+
+	var message string
+	if e.Level >= 0 {
+		message = fmt.Sprintf("%s failed at level %d for %d component(s): %s", e.Phase, e.Level, len(components), strings.Join(parts, "; "))
+	} else {
+		message = fmt.Sprintf("%s failed for %d component(s): %s", e.Phase, len(components), strings.Join(parts, "; "))
+	}
+
+	if e.Rollback != nil {
+		message += fmt.Sprintf(" (rolled back %d component(s), %d failed to stop)", len(e.Rollback.Entries), len(e.Rollback.Failed()))
+	}
+
+	return message
+}
+
+// Unwrap returns the component errors in component name order
+func (e *LifecycleError) Unwrap() []error {
+	components := e.Components()
+
+	errs := make([]error, 0, len(components))
+	for _, name := range components {
+		errs = append(errs, e.Errors[name])
+	}
+	return errs
+}
+
+// Components returns the names of the failed components in sorted order
+func (e *LifecycleError) Components() []string {
+	components := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		components = append(components, name)
+	}
+	sort.Strings(components)
+	return components
+}
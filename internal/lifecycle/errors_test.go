@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLifecycleErrorAggregatesComponentErrors(t *testing.T) {
+	timeoutErr := &TimeoutError{Component: "cache", Operation: "start"}
+	err := &LifecycleError{
+		Phase: PhaseStartup,
+		Level: 1,
+		Errors: map[string]error{
+			"queue": io.ErrUnexpectedEOF,
+			"cache": timeoutErr,
+		},
+	}
+
+	if got := err.Components(); len(got) != 2 || got[0] != "cache" || got[1] != "queue" {
+		t.Errorf("Components() = %v, want [cache queue]", got)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("errors.Is does not find the error of queue")
+	}
+	var target *TimeoutError
+	if !errors.As(err, &target) || target != timeoutErr {
+		t.Error("errors.As does not find the timeout of cache")
+	}
+
+	want := "startup failed at level 1 for 2 component(s): cache: " + timeoutErr.Error() + "; queue: unexpected EOF"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	err.Level = -1
+	err.Rollback = &RollbackReport{Entries: []RollbackEntry{
+		{Component: "database", Stopped: true},
+		{Component: "logger", Error: io.ErrClosedPipe},
+	}}
+	want = "startup failed for 2 component(s): cache: " + timeoutErr.Error() + "; queue: unexpected EOF (rolled back 2 component(s), 1 failed to stop)"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestStartReportsEveryFailedComponentOfLevel(t *testing.T) {
+	failing := func(name string, err error) *testComponent {
+		return &testComponent{
+			name:  name,
+			start: func(ctx context.Context) error { return err },
+		}
+	}
+	lm := newTestManager(t,
+		failing("cache", io.ErrUnexpectedEOF),
+		failing("queue", io.ErrClosedPipe),
+		&testComponent{name: "database"},
+	)
+
+	err := lm.Start(context.Background())
+
+	var lifecycleErr *LifecycleError
+	if !errors.As(err, &lifecycleErr) {
+		t.Fatalf("Start() = %v, want a *LifecycleError", err)
+	}
+	if lifecycleErr.Phase != PhaseStartup || lifecycleErr.Level != 0 {
+		t.Errorf("error is for %s at level %d, want startup at level 0", lifecycleErr.Phase, lifecycleErr.Level)
+	}
+	if got := lifecycleErr.Components(); len(got) != 2 || got[0] != "cache" || got[1] != "queue" {
+		t.Errorf("failed components = %v, want [cache queue]", got)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Start() = %v, want both component errors", err)
+	}
+}
@@ -0,0 +1,112 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// healthMonitor holds the state of a running background health monitor
+type healthMonitor struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// AddHealthTransitionHandler registers a handler called when a component's health status changes
+func (lm *DefaultLifecycleManager) AddHealthTransitionHandler(handler HealthTransitionHandler) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.healthHandlers = append(lm.healthHandlers, handler)
+}
+
+// StartHealthMonitor starts a background loop that health checks all components on the given interval.
+// Results are stored in each component's state, so they can be read through GetComponentState
+// without invoking the components again.
+func (lm *DefaultLifecycleManager) StartHealthMonitor(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("health check interval must be positive, got %s", interval)
+	}
+
+	lm.monitorMu.Lock()
+	defer lm.monitorMu.Unlock()
+
+	if lm.monitor != nil {
+		return fmt.Errorf("health monitor is already running")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	monitor := &healthMonitor{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	lm.monitor = monitor
+
+	go lm.runHealthMonitor(ctx, interval, monitor.done)
+
+	if lm.logger != nil {
+		lm.logger.Info("Health monitor started",
+			"interval", interval,
+		)
+	}
+
+	return nil
+}
+
+// StopHealthMonitor stops the background health monitor and waits for it to exit.
+// It is a no-op if the monitor is not running.
+func (lm *DefaultLifecycleManager) StopHealthMonitor() {
+	lm.monitorMu.Lock()
+	defer lm.monitorMu.Unlock()
+
+	if lm.monitor == nil {
+		return
+	}
+
+	lm.monitor.cancel()
+	<-lm.monitor.done
+	lm.monitor = nil
+
+	if lm.logger != nil {
+		lm.logger.Info("Health monitor stopped")
+	}
+}
+
+// runHealthMonitor runs health checks on every tick until the context is cancelled
+func (lm *DefaultLifecycleManager) runHealthMonitor(ctx context.Context, interval time.Duration, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Only check health while components are running
+			if lm.GetPhase() != PhaseRunning {
+				continue
+			}
+			lm.HealthCheck(ctx)
+			lm.superviseComponents(ctx)
+		}
+	}
+}
+
+// emitHealthTransitions logs and dispatches health transitions to the registered handlers
+func (lm *DefaultLifecycleManager) emitHealthTransitions(ctx context.Context, handlers []HealthTransitionHandler, transitions []HealthTransition) {
+	for _, transition := range transitions {
+		if lm.logger != nil {
+			lm.logger.Info("Component health changed",
+				"component", transition.Component,
+				"from", transition.Previous.Status,
+				"to", transition.Current.Status,
+			)
+		}
+
+		for _, handler := range handlers {
+			handler(ctx, transition)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartHealthMonitorRejectsInvalidUse(t *testing.T) {
+	lm := newTestManager(t)
+	if err := lm.StartHealthMonitor(0); err == nil {
+		t.Error("StartHealthMonitor(0) succeeded, want an error")
+	}
+	if err := lm.StartHealthMonitor(time.Hour); err != nil {
+		t.Fatalf("StartHealthMonitor() = %v", err)
+	}
+	defer lm.StopHealthMonitor()
+	if err := lm.StartHealthMonitor(time.Hour); err == nil {
+		t.Error("second StartHealthMonitor() succeeded, want an error")
+	}
+}
+
+func TestHealthMonitorCachesHealthAndReportsTransitions(t *testing.T) {
+	var status atomic.Value
+	status.Store(HealthStatusHealthy)
+	var checks atomic.Int32
+	lm := newTestManager(t, &testComponent{
+		name: "database",
+		health: func(ctx context.Context) ComponentHealth {
+			checks.Add(1)
+			return ComponentHealth{Status: status.Load().(HealthStatus)}
+		},
+	})
+
+	var mu sync.Mutex
+	var transitions []HealthTransition
+	lm.AddHealthTransitionHandler(func(ctx context.Context, transition HealthTransition) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, transition)
+	})
+
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+	if err := lm.StartHealthMonitor(time.Millisecond); err != nil {
+		t.Fatalf("StartHealthMonitor() = %v", err)
+	}
+	defer lm.StopHealthMonitor()
+
+	waitForHealth := func(want HealthStatus) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			if state, _ := lm.GetComponentState("database"); state.Health.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("cached health never became %s", want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitForHealth(HealthStatusHealthy)
+	status.Store(HealthStatusDegraded)
+	waitForHealth(HealthStatusDegraded)
+	lm.StopHealthMonitor()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transitions) != 2 {
+		t.Fatalf("got %d transitions, want unknown to healthy and healthy to degraded: %+v", len(transitions), transitions)
+	}
+	second := transitions[1]
+	if second.Component != "database" || second.Previous.Status != HealthStatusHealthy || second.Current.Status != HealthStatusDegraded {
+		t.Errorf("second transition = %+v, want database from healthy to degraded", second)
+	}
+
+	// No checks run once the monitor is stopped
+	stopped := checks.Load()
+	time.Sleep(10 * time.Millisecond)
+	if n := checks.Load(); n != stopped {
+		t.Errorf("%d health checks ran after StopHealthMonitor", n-stopped)
+	}
+}
+
+func TestHealthMonitorSkipsChecksUntilRunning(t *testing.T) {
+	var checks atomic.Int32
+	lm := newTestManager(t, &testComponent{
+		name: "database",
+		health: func(ctx context.Context) ComponentHealth {
+			checks.Add(1)
+			return ComponentHealth{Status: HealthStatusHealthy}
+		},
+	})
+	if err := lm.StartHealthMonitor(time.Millisecond); err != nil {
+		t.Fatalf("StartHealthMonitor() = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	lm.StopHealthMonitor()
+
+	if n := checks.Load(); n != 0 {
+		t.Errorf("%d health checks ran before Start", n)
+	}
+	// Stopping a stopped monitor is a no-op
+	lm.StopHealthMonitor()
+}
@@ -2,21 +2,38 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/AnasImloul/go-orchestrator/internal/logger"
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
 )
 
 // DefaultLifecycleManager implements the LifecycleManager interface
 type DefaultLifecycleManager struct {
-	dag    *DAG
-	hooks  map[Phase][]Hook
-	states map[string]*ComponentState
-	phase  Phase
-	logger logger.Logger
-	mu     sync.RWMutex
+	dag             *DAG
+	hooks           map[Phase][]Hook
+	hooksMu         sync.RWMutex // Guards hooks, which fire while components start and stop without mu
+	states          map[string]*ComponentState
+	phase           Phase
+	logger          logger.Logger
+	startTimeout    time.Duration
+	stopTimeout     time.Duration
+	startupTimeout  time.Duration
+	shutdownTimeout time.Duration
+	healthHandlers  []HealthTransitionHandler
+	startupReport   *StartupReport
+	metrics         MetricsRecorder
+	tracer          tracing.Tracer
+	monitor         *healthMonitor
+	monitorMu       sync.Mutex
+	restartMu       sync.Mutex         // Serializes restarts, which run without mu while components restart
+	restartCancel   context.CancelFunc // Cancels the running restart, if any
+	stateMu         sync.Mutex         // Guards the fields of component states; mu guards the states map
+	mu              sync.RWMutex
 }
 
 // NewLifecycleManager creates a new lifecycle manager
@@ -95,11 +112,84 @@ func (lm *DefaultLifecycleManager) UnregisterComponent(name string) error {
 	return nil
 }
 
+// StartComponent starts a single component while the lifecycle manager is running.
+// All of the component's dependencies must already be running.
+func (lm *DefaultLifecycleManager) StartComponent(ctx context.Context, name string) error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if lm.phase != PhaseRunning {
+		return fmt.Errorf("cannot start component %s: lifecycle manager is not running (current: %s)", name, lm.phase)
+	}
+
+	node, exists := lm.dag.GetNode(name)
+	if !exists {
+		return fmt.Errorf("component %s is not registered", name)
+	}
+
+	for _, dep := range node.Dependencies {
+		state, exists := lm.states[dep]
+		if !exists {
+			return fmt.Errorf("cannot start component %s: missing dependency %s", name, dep)
+		}
+		if phase := lm.componentPhase(state); phase != PhaseRunning {
+			return fmt.Errorf("cannot start component %s: dependency %s is not running (current: %s)", name, dep, phase)
+		}
+	}
+
+	return lm.startComponent(ctx, node, lm.states[name])
+}
+
+// StopComponent stops a single component while the lifecycle manager is running.
+// It refuses to stop a component while any of its dependents are still running.
+func (lm *DefaultLifecycleManager) StopComponent(ctx context.Context, name string) error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if lm.phase != PhaseRunning {
+		return fmt.Errorf("cannot stop component %s: lifecycle manager is not running (current: %s)", name, lm.phase)
+	}
+
+	node, exists := lm.dag.GetNode(name)
+	if !exists {
+		return fmt.Errorf("component %s is not registered", name)
+	}
+
+	for _, dependent := range lm.dag.GetDependents(name) {
+		if lm.componentPhase(lm.states[dependent]) == PhaseRunning {
+			return fmt.Errorf("cannot stop component %s: dependent %s is still running", name, dependent)
+		}
+	}
+
+	return lm.stopComponent(ctx, node, lm.states[name])
+}
+
+// GetDependents returns the names of the components that depend on the given component
+func (lm *DefaultLifecycleManager) GetDependents(name string) []string {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+
+	dependents := lm.dag.GetDependents(name)
+	sort.Strings(dependents)
+	return dependents
+}
+
 // Start starts all components in dependency order
-func (lm *DefaultLifecycleManager) Start(ctx context.Context) error {
+func (lm *DefaultLifecycleManager) Start(ctx context.Context) (err error) {
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
 
+	ctx, span := lm.getTracer().Start(ctx, "lifecycle.start",
+		tracing.Int("lifecycle.components", len(lm.states)),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	// Bound how long startup waits for components, without cancelling the context they keep after starting
+	ctx, stopWaiting := withWaitTimeout(ctx, lm.startupTimeout)
+	defer stopWaiting()
+
 	if lm.phase != PhaseStopped {
 		return fmt.Errorf("lifecycle manager is not in stopped phase (current: %s)", lm.phase)
 	}
@@ -123,6 +213,8 @@ func (lm *DefaultLifecycleManager) Start(ctx context.Context) error {
 	}
 
 	// Start components level by level, with parallel execution within each level
+	startupStart := time.Now()
+	var levelDurations []time.Duration
 	for levelIndex, level := range startupLevels {
 		if lm.logger != nil {
 			lm.logger.Info("Starting components at level",
@@ -131,43 +223,77 @@ func (lm *DefaultLifecycleManager) Start(ctx context.Context) error {
 			)
 		}
 
-		if err := lm.startComponentsInParallel(ctx, level); err != nil {
+		levelStart := time.Now()
+		levelCtx, levelSpan := lm.getTracer().Start(ctx, "lifecycle.start.level",
+			tracing.Int("lifecycle.level", levelIndex),
+			tracing.Strings("lifecycle.components", nodeNames(level)),
+		)
+		errs := lm.startComponentsInParallel(levelCtx, level)
+		levelDurations = append(levelDurations, time.Since(levelStart))
+		tracing.End(levelSpan, joinComponentErrors(errs))
+
+		if len(errs) > 0 {
+			lm.startupReport = lm.buildStartupReport(startupStart, startupLevels, levelDurations)
+			startErr := &LifecycleError{
+				Phase:  PhaseStartup,
+				Level:  levelIndex,
+				Errors: errs,
+			}
+
 			if lm.logger != nil {
 				lm.logger.Error("Failed to start components at level, initiating rollback",
 					"level", levelIndex,
-					"error", err.Error(),
+					"error", startErr.Error(),
 				)
 			}
 
 			// Rollback: stop all started components
-			lm.rollbackStartup(ctx, "")
+			startErr.Rollback = lm.rollbackStartup(ctx, startupLevels, levelIndex)
 			lm.phase = PhaseStopped
-			return fmt.Errorf("failed to start components at level %d: %w", levelIndex, err)
+			return startErr
 		}
 	}
 
+	lm.startupReport = lm.buildStartupReport(startupStart, startupLevels, levelDurations)
 	lm.phase = PhaseRunning
 	if lm.logger != nil {
-		lm.logger.Info("All components started successfully")
+		lm.logger.Info("All components started successfully",
+			"duration", lm.startupReport.Duration,
+		)
 	}
 
 	return nil
 }
 
 // Stop stops all components in reverse dependency order
-func (lm *DefaultLifecycleManager) Stop(ctx context.Context) error {
+func (lm *DefaultLifecycleManager) Stop(ctx context.Context) (err error) {
+	// Stop the health monitor and any restart first so they don't race with component shutdown.
+	// Restarts run without the lock, so enter the shutdown phase first to keep new ones from beginning.
+	lm.StopHealthMonitor()
+	lm.mu.Lock()
+	previousPhase := lm.phase
+	lm.phase = PhaseShutdown
+	lm.mu.Unlock()
+	lm.cancelRestarts()
+
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
 
-	if lm.phase != PhaseRunning {
+	ctx, span := lm.getTracer().Start(ctx, "lifecycle.stop",
+		tracing.Int("lifecycle.components", len(lm.states)),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	if previousPhase != PhaseRunning {
 		if lm.logger != nil {
 			lm.logger.Warn("Attempting to stop lifecycle manager not in running phase",
-				"current_phase", lm.phase,
+				"current_phase", previousPhase,
 			)
 		}
 	}
 
-	lm.phase = PhaseShutdown
 	if lm.logger != nil {
 		lm.logger.Info("Stopping lifecycle manager")
 	}
@@ -181,29 +307,54 @@ func (lm *DefaultLifecycleManager) Stop(ctx context.Context) error {
 		}
 	}
 
-	// Get shutdown order
-	shutdownOrder, err := lm.dag.GetShutdownOrder()
+	// Get shutdown levels for parallel execution
+	shutdownLevels, err := lm.dag.GetShutdownLevels()
 	if err != nil {
 		if lm.logger != nil {
-			lm.logger.Error("Failed to determine shutdown order",
+			lm.logger.Error("Failed to determine shutdown levels",
 				"error", err.Error(),
 			)
 		}
-		// Continue with best effort shutdown
-		shutdownOrder = lm.getAllNodesInReverseOrder()
+		// Continue with best effort shutdown, one component at a time
+		shutdownLevels = nil
+		for _, node := range lm.getAllNodesInReverseOrder() {
+			shutdownLevels = append(shutdownLevels, []*Node{node})
+		}
 	}
 
-	// Stop components in order
-	var lastError error
-	for _, node := range shutdownOrder {
-		if err := lm.stopComponent(ctx, node); err != nil {
-			if lm.logger != nil {
-				lm.logger.Error("Failed to stop component",
-					"component", node.Name,
-					"error", err.Error(),
-				)
-			}
-			lastError = err
+	// Stop components level by level, dependents first, with parallel execution within each level.
+	// Keep going after failures so that every component gets a chance to stop.
+	stopErr := &LifecycleError{
+		Phase:  PhaseShutdown,
+		Level:  -1,
+		Errors: make(map[string]error),
+	}
+	failedLevels := make(map[int]bool)
+	for levelIndex, level := range shutdownLevels {
+		if lm.logger != nil {
+			lm.logger.Info("Stopping components at level",
+				"level", levelIndex,
+				"components", len(level),
+			)
+		}
+
+		levelCtx, levelSpan := lm.getTracer().Start(ctx, "lifecycle.stop.level",
+			tracing.Int("lifecycle.level", len(shutdownLevels)-1-levelIndex),
+			tracing.Strings("lifecycle.components", nodeNames(level)),
+		)
+		errs := lm.stopComponentsInParallel(levelCtx, level)
+		tracing.End(levelSpan, joinComponentErrors(errs))
+
+		for name, err := range errs {
+			stopErr.Errors[name] = err
+			failedLevels[levelIndex] = true
+		}
+	}
+
+	// Report the DAG level when all failures happened within a single level
+	if len(failedLevels) == 1 && len(shutdownLevels) > 0 {
+		for levelIndex := range failedLevels {
+			stopErr.Level = len(shutdownLevels) - 1 - levelIndex
 		}
 	}
 
@@ -212,13 +363,17 @@ func (lm *DefaultLifecycleManager) Stop(ctx context.Context) error {
 		lm.logger.Info("Lifecycle manager stopped")
 	}
 
-	return lastError
+	if len(stopErr.Errors) > 0 {
+		return stopErr
+	}
+
+	return nil
 }
 
 // AddHook adds a lifecycle hook for a specific phase
 func (lm *DefaultLifecycleManager) AddHook(phase Phase, hook Hook) error {
-	lm.mu.Lock()
-	defer lm.mu.Unlock()
+	lm.hooksMu.Lock()
+	defer lm.hooksMu.Unlock()
 
 	lm.hooks[phase] = append(lm.hooks[phase], hook)
 
@@ -233,8 +388,8 @@ func (lm *DefaultLifecycleManager) AddHook(phase Phase, hook Hook) error {
 
 // RemoveHook removes a lifecycle hook
 func (lm *DefaultLifecycleManager) RemoveHook(phase Phase, hook Hook) error {
-	lm.mu.Lock()
-	defer lm.mu.Unlock()
+	lm.hooksMu.Lock()
+	defer lm.hooksMu.Unlock()
 
 	hooks := lm.hooks[phase]
 	for i, h := range hooks {
@@ -259,8 +414,11 @@ func (lm *DefaultLifecycleManager) GetComponentState(name string) (ComponentStat
 	defer lm.mu.RUnlock()
 
 	if state, exists := lm.states[name]; exists {
+		lm.stateMu.Lock()
+		defer lm.stateMu.Unlock()
+
 		// Return a copy to prevent external modification
-		return *state, true
+		return copyComponentState(state), true
 	}
 
 	return ComponentState{}, false
@@ -271,10 +429,13 @@ func (lm *DefaultLifecycleManager) GetAllComponentStates() map[string]ComponentS
 	lm.mu.RLock()
 	defer lm.mu.RUnlock()
 
+	lm.stateMu.Lock()
+	defer lm.stateMu.Unlock()
+
 	// Return copies to prevent external modification
 	states := make(map[string]ComponentState)
 	for name, state := range lm.states {
-		states[name] = *state
+		states[name] = copyComponentState(state)
 	}
 
 	return states
@@ -290,64 +451,121 @@ func (lm *DefaultLifecycleManager) GetPhase() Phase {
 
 // HealthCheck performs a health check on all components
 func (lm *DefaultLifecycleManager) HealthCheck(ctx context.Context) map[string]ComponentHealth {
-	lm.mu.RLock()
-	defer lm.mu.RUnlock()
-
-	health := make(map[string]ComponentHealth)
-
-	// Get components in dependency order (dependencies first)
-	startupOrder, err := lm.dag.GetStartupOrder()
+	// Snapshot the components in dependency order (dependencies first). The DAG
+	// traversal mutates visit flags, so it needs the write lock.
+	lm.mu.Lock()
+	nodes, err := lm.dag.GetStartupOrder()
 	if err != nil {
-		// If we can't get the order, fall back to the original approach
-		for name, state := range lm.states {
-			if node, exists := lm.dag.GetNode(name); exists {
-				componentHealth := node.Component.Health(ctx)
-				health[name] = componentHealth
-
-				// Update the stored state
-				state.Health = componentHealth
-			} else {
-				health[name] = ComponentHealth{
-					Status:    HealthStatusUnknown,
-					Message:   "Component not found in DAG",
-					Timestamp: time.Now(),
-				}
-			}
-		}
-		return health
+		// If we can't get the order, fall back to all registered components
+		nodes = lm.getAllNodesInReverseOrder()
 	}
+	lm.mu.Unlock()
 
-	// Health check in dependency order
-	for _, node := range startupOrder {
-		if state, exists := lm.states[node.Name]; exists {
-			componentHealth := node.Component.Health(ctx)
-			health[node.Name] = componentHealth
+	// Call component health methods without holding the lock, since components
+	// may read the state of their dependencies while reporting health
+	health := make(map[string]ComponentHealth, len(nodes))
+	for _, node := range nodes {
+		health[node.Name] = node.Component.Health(ctx)
+	}
 
-			// Update the stored state
-			state.Health = componentHealth
-		} else {
-			health[node.Name] = ComponentHealth{
-				Status:    HealthStatusUnknown,
-				Message:   "Component state not found",
+	// Store the results and detect status changes
+	var transitions []HealthTransition
+	lm.mu.Lock()
+	lm.stateMu.Lock()
+	for name, componentHealth := range health {
+		state, exists := lm.states[name]
+		if !exists {
+			// Component was unregistered while its health was being checked
+			delete(health, name)
+			continue
+		}
+
+		if state.Health.Status != componentHealth.Status {
+			transitions = append(transitions, HealthTransition{
+				Component: name,
+				Previous:  state.Health,
+				Current:   componentHealth,
 				Timestamp: time.Now(),
-			}
+			})
+		}
+
+		// Update the stored state
+		state.Health = componentHealth
+
+		if lm.metrics != nil {
+			lm.metrics.RecordComponentHealth(name, componentHealth.Status)
 		}
 	}
+	lm.stateMu.Unlock()
+	handlers := make([]HealthTransitionHandler, len(lm.healthHandlers))
+	copy(handlers, lm.healthHandlers)
+	lm.mu.Unlock()
+
+	lm.emitHealthTransitions(ctx, handlers, transitions)
 
 	return health
 }
 
+// SetDefaultTimeouts sets the start and stop timeouts used for components that don't specify their own.
+// A zero timeout disables the limit.
+func (lm *DefaultLifecycleManager) SetDefaultTimeouts(startTimeout, stopTimeout time.Duration) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.startTimeout = startTimeout
+	lm.stopTimeout = stopTimeout
+}
+
+// SetStartupTimeout sets how long Start waits for all components to start. A zero timeout disables the limit.
+// Components that are still starting when it expires are abandoned and the startup is rolled back.
+func (lm *DefaultLifecycleManager) SetStartupTimeout(timeout time.Duration) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.startupTimeout = timeout
+}
+
+// SetShutdownTimeout sets how long the rollback of a failed startup waits for components to stop.
+// A zero timeout disables the limit. Each component's Stop call stays bounded by its own stop timeout.
+func (lm *DefaultLifecycleManager) SetShutdownTimeout(timeout time.Duration) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.shutdownTimeout = timeout
+}
+
+// SetTracer sets the tracer used to create spans for startup, shutdown and component operations
+func (lm *DefaultLifecycleManager) SetTracer(tracer tracing.Tracer) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.tracer = tracer
+}
+
+// SetMetricsRecorder sets the recorder that receives lifecycle metrics
+func (lm *DefaultLifecycleManager) SetMetricsRecorder(recorder MetricsRecorder) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.metrics = recorder
+}
+
 // Private helper methods
 
-// startComponentsInParallel starts multiple components in parallel
-func (lm *DefaultLifecycleManager) startComponentsInParallel(ctx context.Context, nodes []*Node) error {
+// startComponentsInParallel starts multiple components in parallel and returns the errors keyed by component name.
+// The caller must hold the lock.
+func (lm *DefaultLifecycleManager) startComponentsInParallel(ctx context.Context, nodes []*Node) map[string]error {
+	errs := make(map[string]error)
 	if len(nodes) == 0 {
-		return nil
+		return errs
 	}
 
 	if len(nodes) == 1 {
 		// Single component, no need for goroutines
-		return lm.startComponent(ctx, nodes[0])
+		if err := lm.startComponent(ctx, nodes[0], lm.states[nodes[0].Name]); err != nil {
+			errs[nodes[0].Name] = err
+		}
+		return errs
 	}
 
 	// Use goroutines for parallel execution
@@ -360,39 +578,57 @@ func (lm *DefaultLifecycleManager) startComponentsInParallel(ctx context.Context
 
 	// Start all components in parallel
 	for _, node := range nodes {
-		go func(n *Node) {
-			err := lm.startComponent(ctx, n)
+		go func(n *Node, state *ComponentState) {
+			err := lm.startComponent(ctx, n, state)
 			results <- result{node: n, err: err}
-		}(node)
+		}(node, lm.states[node.Name])
 	}
 
 	// Collect results
-	var errors []error
 	for i := 0; i < len(nodes); i++ {
 		res := <-results
 		if res.err != nil {
-			errors = append(errors, fmt.Errorf("component %s: %w", res.node.Name, res.err))
+			errs[res.node.Name] = res.err
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to start components: %v", errors)
-	}
-
-	return nil
+	return errs
 }
 
-// startComponent starts a single component
-func (lm *DefaultLifecycleManager) startComponent(ctx context.Context, node *Node) (err error) {
+// startComponent starts a single component that is stopped and records the outcome in its state.
+// It doesn't need the lock, so that restarts don't block other operations while components start.
+func (lm *DefaultLifecycleManager) startComponent(ctx context.Context, node *Node, state *ComponentState) (err error) {
+	name := node.Name
+
+	// Claim the component, so that concurrent operations don't start it twice
+	lm.stateMu.Lock()
+	if phase := state.Phase; phase != PhaseStopped {
+		lm.stateMu.Unlock()
+		return fmt.Errorf("cannot start component %s: component is not stopped (current: %s)", name, phase)
+	}
+	state.Phase = PhaseStartup
+	now := time.Now()
+	state.StartedAt = &now
+	lm.stateMu.Unlock()
+
 	// Add panic recovery
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("panic during component start %s: %v", node.Name, r)
+			err = fmt.Errorf("panic during component start %s: %v", name, r)
+			lm.stateMu.Lock()
+			state.Phase = PhaseStopped
+			state.Error = err
+			state.StartedAt = nil
+			lm.stateMu.Unlock()
 		}
 	}()
 
-	name := node.Name
-	state := lm.states[name]
+	ctx, span := lm.getTracer().Start(ctx, "lifecycle.component.start",
+		tracing.String("lifecycle.component", name),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
 
 	if lm.logger != nil {
 		lm.logger.Info("Starting component",
@@ -400,31 +636,46 @@ func (lm *DefaultLifecycleManager) startComponent(ctx context.Context, node *Nod
 		)
 	}
 
-	// Update state
-	state.Phase = PhaseStartup
-	now := time.Now()
-	state.StartedAt = &now
-
-	// Start the component with retry logic if configured
+	// Start the component with retry logic if configured, bounding each attempt by the start timeout
+	timeout := lm.componentTimeout(node.Component, "start")
+	attempts := 0
+	attempt := func() error {
+		attempts++
+		return callWithTimeout(ctx, name, "start", timeout, node.Component.Start)
+	}
 	var startErr error
 	if retryConfig := node.Component.GetRetryConfig(); retryConfig != nil {
-		startErr = RetryWithBackoff(ctx, *retryConfig, func() error {
-			return node.Component.Start(ctx)
-		})
+		startErr = RetryWithBackoff(waitContext(ctx), *retryConfig, attempt)
 	} else {
-		startErr = node.Component.Start(ctx)
+		startErr = attempt()
+	}
+
+	duration := time.Since(now)
+	span.SetAttributes(
+		tracing.Int("lifecycle.attempts", attempts),
+		tracing.Int("lifecycle.retries", attempts-1),
+		tracing.String("lifecycle.timeout", timeout.String()),
+	)
+
+	if lm.metrics != nil {
+		lm.metrics.RecordComponentStart(name, duration, attempts, startErr)
 	}
 
+	lm.stateMu.Lock()
+	state.StartDuration = duration
+	state.StartAttempts = attempts
 	if startErr != nil {
 		state.Phase = PhaseStopped
 		state.Error = startErr
 		state.StartedAt = nil
+		lm.stateMu.Unlock()
 		return startErr
 	}
 
 	// Update state
 	state.Phase = PhaseRunning
 	state.Error = nil
+	lm.stateMu.Unlock()
 
 	// Fire component-specific startup hooks
 	if err := lm.fireHooks(ctx, PhaseStartup, name, map[string]interface{}{
@@ -442,20 +693,86 @@ func (lm *DefaultLifecycleManager) startComponent(ctx context.Context, node *Nod
 	return nil
 }
 
-// stopComponent stops a single component
-func (lm *DefaultLifecycleManager) stopComponent(ctx context.Context, node *Node) error {
+// stopComponentsInParallel stops multiple components in parallel and returns the errors keyed by component name.
+// The caller must hold the lock.
+func (lm *DefaultLifecycleManager) stopComponentsInParallel(ctx context.Context, nodes []*Node) map[string]error {
+	errs := make(map[string]error)
+	if len(nodes) == 0 {
+		return errs
+	}
+
+	if len(nodes) == 1 {
+		// Single component, no need for goroutines
+		if err := lm.stopComponent(ctx, nodes[0], lm.states[nodes[0].Name]); err != nil {
+			errs[nodes[0].Name] = err
+		}
+		return errs
+	}
+
+	// Use goroutines for parallel execution
+	type result struct {
+		node *Node
+		err  error
+	}
+
+	results := make(chan result, len(nodes))
+
+	// Stop all components in parallel
+	for _, node := range nodes {
+		go func(n *Node, state *ComponentState) {
+			err := lm.stopComponent(ctx, n, state)
+			results <- result{node: n, err: err}
+		}(node, lm.states[node.Name])
+	}
+
+	// Collect results
+	for i := 0; i < len(nodes); i++ {
+		res := <-results
+		if res.err != nil {
+			errs[res.node.Name] = res.err
+		}
+	}
+
+	return errs
+}
+
+// stopComponent stops a single component if it is running and records the outcome in its state.
+// It doesn't need the lock, so that restarts don't block other operations while components stop.
+func (lm *DefaultLifecycleManager) stopComponent(ctx context.Context, node *Node, state *ComponentState) (err error) {
 	name := node.Name
-	state := lm.states[name]
 
-	if state.Phase != PhaseRunning {
+	// Claim the component, so that concurrent operations don't stop it twice
+	lm.stateMu.Lock()
+	if phase := state.Phase; phase != PhaseRunning {
+		lm.stateMu.Unlock()
 		if lm.logger != nil {
 			lm.logger.Debug("Skipping component stop (not running)",
 				"component", name,
-				"phase", state.Phase,
+				"phase", phase,
 			)
 		}
 		return nil
 	}
+	state.Phase = PhaseShutdown
+	lm.stateMu.Unlock()
+
+	// Add panic recovery
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic during component stop %s: %v", name, r)
+			lm.stateMu.Lock()
+			state.Phase = PhaseStopped
+			state.StopError = err
+			lm.stateMu.Unlock()
+		}
+	}()
+
+	ctx, span := lm.getTracer().Start(ctx, "lifecycle.component.stop",
+		tracing.String("lifecycle.component", name),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
 
 	if lm.logger != nil {
 		lm.logger.Info("Stopping component",
@@ -463,21 +780,34 @@ func (lm *DefaultLifecycleManager) stopComponent(ctx context.Context, node *Node
 		)
 	}
 
-	// Update state
-	state.Phase = PhaseShutdown
+	stopStart := time.Now()
 
-	// Stop the component with retry logic if configured
+	// Stop the component with retry logic if configured, bounding each attempt by the stop timeout
+	timeout := lm.componentTimeout(node.Component, "stop")
+	attempts := 0
+	attempt := func() error {
+		attempts++
+		return callWithTimeout(ctx, name, "stop", timeout, node.Component.Stop)
+	}
 	var stopErr error
 	if retryConfig := node.Component.GetRetryConfig(); retryConfig != nil {
-		stopErr = RetryWithBackoff(ctx, *retryConfig, func() error {
-			return node.Component.Stop(ctx)
-		})
+		stopErr = RetryWithBackoff(waitContext(ctx), *retryConfig, attempt)
 	} else {
-		stopErr = node.Component.Stop(ctx)
+		stopErr = attempt()
+	}
+
+	duration := time.Since(stopStart)
+	span.SetAttributes(
+		tracing.Int("lifecycle.attempts", attempts),
+		tracing.Int("lifecycle.retries", attempts-1),
+		tracing.String("lifecycle.timeout", timeout.String()),
+	)
+
+	if lm.metrics != nil {
+		lm.metrics.RecordComponentStop(name, duration, attempts, stopErr)
 	}
 
 	if stopErr != nil {
-		state.Error = stopErr
 		if lm.logger != nil {
 			lm.logger.Error("Component stop failed",
 				"component", name,
@@ -488,9 +818,14 @@ func (lm *DefaultLifecycleManager) stopComponent(ctx context.Context, node *Node
 	}
 
 	// Update state
+	lm.stateMu.Lock()
+	state.StopDuration = duration
+	state.StopAttempts = attempts
+	state.StopError = stopErr
 	state.Phase = PhaseStopped
 	now := time.Now()
 	state.StoppedAt = &now
+	lm.stateMu.Unlock()
 
 	// Fire component-specific shutdown hooks
 	if err := lm.fireHooks(ctx, PhaseShutdown, name, map[string]interface{}{
@@ -505,30 +840,56 @@ func (lm *DefaultLifecycleManager) stopComponent(ctx context.Context, node *Node
 		)
 	}
 
-	return state.Error
+	return stopErr
 }
 
-// rollbackStartup stops all components that were started before a failure
-func (lm *DefaultLifecycleManager) rollbackStartup(ctx context.Context, failedComponent string) {
+// rollbackStartup stops the components that reached the running phase before a startup failure.
+// Levels up to and including the failed level are rolled back in reverse order, so a component
+// is only stopped after all of its dependents. The rollback runs on a context detached from the
+// startup context, which may already have expired, and waits for at most the shutdown timeout.
+func (lm *DefaultLifecycleManager) rollbackStartup(ctx context.Context, levels [][]*Node, failedLevel int) *RollbackReport {
 	if lm.logger != nil {
 		lm.logger.Warn("Rolling back startup",
-			"failed_component", failedComponent,
+			"failed_level", failedLevel,
 		)
 	}
 
-	// Get all nodes and stop those that are running
-	nodes := lm.dag.GetAllNodes()
-	for name, node := range nodes {
-		if name == failedComponent {
-			break // Don't try to stop the failed component
+	rollbackCtx, stopWaiting := withWaitTimeout(context.WithoutCancel(ctx), lm.shutdownTimeout)
+	defer stopWaiting()
+
+	report := &RollbackReport{}
+	for levelIndex := failedLevel; levelIndex >= 0; levelIndex-- {
+		// Only stop components that actually reached the running phase
+		var running []*Node
+		for _, node := range levels[levelIndex] {
+			if lm.componentPhase(lm.states[node.Name]) == PhaseRunning {
+				running = append(running, node)
+			}
 		}
 
-		if state := lm.states[name]; state.Phase == PhaseRunning {
-			if err := lm.stopComponent(ctx, node); err != nil {
-				lm.logger.Warn("Failed to stop component during cleanup", "component", name, "error", err.Error())
+		errs := lm.stopComponentsInParallel(rollbackCtx, running)
+
+		sort.Slice(running, func(i, j int) bool {
+			return running[i].Name < running[j].Name
+		})
+		for _, node := range running {
+			entry := RollbackEntry{
+				Component: node.Name,
+				Level:     levelIndex,
+				Stopped:   errs[node.Name] == nil,
+				Error:     errs[node.Name],
 			}
+			if entry.Error != nil && lm.logger != nil {
+				lm.logger.Warn("Failed to stop component during rollback",
+					"component", node.Name,
+					"error", entry.Error.Error(),
+				)
+			}
+			report.Entries = append(report.Entries, entry)
 		}
 	}
+
+	return report
 }
 
 // getAllNodesInReverseOrder returns all nodes in reverse registration order as fallback
@@ -549,9 +910,152 @@ func (lm *DefaultLifecycleManager) getAllNodesInReverseOrder() []*Node {
 	return result
 }
 
+// componentPhase returns the current phase of a component
+func (lm *DefaultLifecycleManager) componentPhase(state *ComponentState) Phase {
+	lm.stateMu.Lock()
+	defer lm.stateMu.Unlock()
+
+	return state.Phase
+}
+
+// componentTimeout returns the timeout for a component operation, falling back to the manager default
+func (lm *DefaultLifecycleManager) componentTimeout(component Component, operation string) time.Duration {
+	if timeoutComponent, ok := component.(TimeoutComponent); ok {
+		var timeout time.Duration
+		if operation == "start" {
+			timeout = timeoutComponent.GetStartTimeout()
+		} else {
+			timeout = timeoutComponent.GetStopTimeout()
+		}
+		if timeout > 0 {
+			return timeout
+		}
+	}
+
+	if operation == "start" {
+		return lm.startTimeout
+	}
+	return lm.stopTimeout
+}
+
+// waitKey is the context key of the context bounding how long the manager waits for component operations
+type waitKey struct{}
+
+// withWaitTimeout returns a context whose component operations are waited for at most timeout, replacing
+// any bound inherited from ctx. Unlike a deadline on ctx itself, the bound doesn't cancel the context the
+// components receive, so they may keep using it once their operation returns. A zero timeout only waits
+// as long as ctx lasts. The returned function releases the bound.
+func withWaitTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	wait, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		wait, cancel = context.WithTimeout(ctx, timeout)
+	}
+	return context.WithValue(ctx, waitKey{}, wait), cancel
+}
+
+// waitContext returns the context bounding how long to wait for component operations run with ctx
+func waitContext(ctx context.Context) context.Context {
+	if wait, ok := ctx.Value(waitKey{}).(context.Context); ok {
+		return wait
+	}
+	return ctx
+}
+
+// callWithTimeout runs a component operation and waits for it for at most timeout.
+// If the operation doesn't return in time it is abandoned and a TimeoutError naming the
+// component is returned. If the wait bound of ctx ends first, such as when the overall
+// startup deadline expires, the operation is abandoned with the error of that bound instead.
+// The operation's context is only cancelled when it is abandoned, which tells it to give up;
+// once the operation returns, background work it started may keep using the context.
+func callWithTimeout(ctx context.Context, name, operation string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	wait := waitContext(ctx)
+	if timeout <= 0 && wait.Done() == ctx.Done() {
+		return fn(ctx)
+	}
+
+	// The operation's context is deliberately left alive when the operation returns in time
+	opCtx, cancel := context.WithCancel(ctx)
+	abandon := func() { cancel() }
+
+	// Buffered so the goroutine can exit even if the operation is abandoned
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic during component %s %s: %v", operation, name, r)
+			}
+		}()
+		done <- fn(opCtx)
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		abandon()
+		return &TimeoutError{Component: name, Operation: operation, Timeout: timeout}
+	case <-wait.Done():
+		abandon()
+		return fmt.Errorf("component %s %s abandoned: %w", name, operation, wait.Err())
+	}
+}
+
+// getTracer returns the configured tracer, or a no-op tracer if none is configured
+func (lm *DefaultLifecycleManager) getTracer() tracing.Tracer {
+	if lm.tracer == nil {
+		return tracing.NoopTracer()
+	}
+	return lm.tracer
+}
+
+// nodeNames returns the names of the nodes
+func nodeNames(nodes []*Node) []string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name
+	}
+	return names
+}
+
+// joinComponentErrors joins per-component errors into one error, or returns nil if there are none
+func joinComponentErrors(errs map[string]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	joined := make([]error, len(names))
+	for i, name := range names {
+		joined[i] = fmt.Errorf("%s: %w", name, errs[name])
+	}
+	return errors.Join(joined...)
+}
+
+// copyComponentState returns a copy of a component state that shares no mutable data with the original
+func copyComponentState(state *ComponentState) ComponentState {
+	stateCopy := *state
+	if state.Restarts != nil {
+		stateCopy.Restarts = make([]RestartRecord, len(state.Restarts))
+		copy(stateCopy.Restarts, state.Restarts)
+	}
+	return stateCopy
+}
+
 // fireHooks executes all hooks for a given phase
 func (lm *DefaultLifecycleManager) fireHooks(ctx context.Context, phase Phase, component string, data map[string]interface{}) error {
+	lm.hooksMu.RLock()
 	hooks := lm.hooks[phase]
+	lm.hooksMu.RUnlock()
 	if len(hooks) == 0 {
 		return nil
 	}
@@ -0,0 +1,354 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testComponent is a component whose behaviour is set by each test
+type testComponent struct {
+	name         string
+	dependencies []string
+	start        func(ctx context.Context) error
+	stop         func(ctx context.Context) error
+	health       func(ctx context.Context) ComponentHealth
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+	retry        *RetryConfig
+	restart      *RestartConfig
+}
+
+func (c *testComponent) Name() string           { return c.name }
+func (c *testComponent) Dependencies() []string { return c.dependencies }
+
+func (c *testComponent) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+	return c.start(ctx)
+}
+
+func (c *testComponent) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+	return c.stop(ctx)
+}
+
+func (c *testComponent) Health(ctx context.Context) ComponentHealth {
+	if c.health == nil {
+		return ComponentHealth{Status: HealthStatusHealthy}
+	}
+	return c.health(ctx)
+}
+
+func (c *testComponent) GetRetryConfig() *RetryConfig     { return c.retry }
+func (c *testComponent) GetRestartConfig() *RestartConfig { return c.restart }
+func (c *testComponent) GetStartTimeout() time.Duration   { return c.startTimeout }
+func (c *testComponent) GetStopTimeout() time.Duration    { return c.stopTimeout }
+
+// newTestManager creates a lifecycle manager with the given components registered
+func newTestManager(t *testing.T, components ...Component) *DefaultLifecycleManager {
+	t.Helper()
+	lm := NewLifecycleManager(nil)
+	for _, component := range components {
+		if err := lm.RegisterComponent(component); err != nil {
+			t.Fatalf("RegisterComponent(%s) failed: %v", component.Name(), err)
+		}
+	}
+	return lm
+}
+
+// blockUntilDone returns a start or stop function that blocks until its context ends,
+// and closes abandoned once it does
+func blockUntilDone(abandoned chan struct{}) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		<-ctx.Done()
+		close(abandoned)
+		return ctx.Err()
+	}
+}
+
+func TestStartTimeoutAbandonsComponent(t *testing.T) {
+	abandoned := make(chan struct{})
+	lm := newTestManager(t, &testComponent{
+		name:         "slow",
+		start:        blockUntilDone(abandoned),
+		startTimeout: 20 * time.Millisecond,
+	})
+
+	err := lm.Start(context.Background())
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Start() = %v, want a *TimeoutError", err)
+	}
+	if timeoutErr.Component != "slow" || timeoutErr.Operation != "start" || timeoutErr.Timeout != 20*time.Millisecond {
+		t.Errorf("TimeoutError = %+v, want slow start after 20ms", timeoutErr)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("timeout error does not match context.DeadlineExceeded")
+	}
+
+	// The abandoned call is told to give up through its context
+	select {
+	case <-abandoned:
+	case <-time.After(time.Second):
+		t.Fatal("context of the abandoned start was not cancelled")
+	}
+	if state, _ := lm.GetComponentState("slow"); state.Phase != PhaseStopped {
+		t.Errorf("timed out component is %s, want stopped", state.Phase)
+	}
+}
+
+func TestStopTimeoutUsesManagerDefault(t *testing.T) {
+	abandoned := make(chan struct{})
+	lm := newTestManager(t, &testComponent{
+		name: "stuck",
+		stop: blockUntilDone(abandoned),
+	})
+	lm.SetDefaultTimeouts(0, 20*time.Millisecond)
+
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	err := lm.Stop(context.Background())
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Operation != "stop" {
+		t.Fatalf("Stop() = %v, want a stop *TimeoutError", err)
+	}
+	<-abandoned
+}
+
+func TestComponentContextOutlivesSuccessfulStart(t *testing.T) {
+	var started context.Context
+	lm := newTestManager(t, &testComponent{
+		name: "server",
+		start: func(ctx context.Context) error {
+			started = ctx
+			return nil
+		},
+		startTimeout: 10 * time.Millisecond,
+	})
+	lm.SetStartupTimeout(10 * time.Millisecond)
+
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	// Neither the component's timeout nor the startup timeout cancel work started in Start
+	time.Sleep(30 * time.Millisecond)
+	if err := started.Err(); err != nil {
+		t.Errorf("context passed to Start ended with %v after Start returned", err)
+	}
+}
+
+func TestStartupTimeoutAbandonsStartupAndRollsBack(t *testing.T) {
+	abandoned := make(chan struct{})
+	var stopped bool
+	lm := newTestManager(t,
+		&testComponent{
+			name: "database",
+			stop: func(ctx context.Context) error {
+				stopped = true
+				return ctx.Err()
+			},
+		},
+		&testComponent{
+			name:         "api",
+			dependencies: []string{"database"},
+			start:        blockUntilDone(abandoned),
+		},
+	)
+	lm.SetStartupTimeout(20 * time.Millisecond)
+
+	err := lm.Start(context.Background())
+
+	var lifecycleErr *LifecycleError
+	if !errors.As(err, &lifecycleErr) {
+		t.Fatalf("Start() = %v, want a *LifecycleError", err)
+	}
+	apiErr := lifecycleErr.Errors["api"]
+	var timeoutErr *TimeoutError
+	if errors.As(apiErr, &timeoutErr) {
+		t.Errorf("startup deadline reported as the component's own timeout: %v", apiErr)
+	}
+	if !errors.Is(apiErr, context.DeadlineExceeded) || !strings.Contains(apiErr.Error(), "abandoned") {
+		t.Errorf("api error = %v, want the start abandoned at the startup deadline", apiErr)
+	}
+	<-abandoned
+
+	// The rollback isn't cut short by the expired startup deadline
+	if !stopped {
+		t.Error("database was not stopped during rollback")
+	}
+	if lifecycleErr.Rollback == nil || len(lifecycleErr.Rollback.Failed()) != 0 {
+		t.Errorf("rollback = %+v, want database stopped", lifecycleErr.Rollback)
+	}
+}
+
+func TestRollbackWaitsForShutdownTimeout(t *testing.T) {
+	abandoned := make(chan struct{})
+	lm := newTestManager(t,
+		&testComponent{
+			name: "database",
+			stop: blockUntilDone(abandoned),
+		},
+		&testComponent{
+			name:         "api",
+			dependencies: []string{"database"},
+			start:        func(ctx context.Context) error { return errors.New("port in use") },
+		},
+	)
+	lm.SetDefaultTimeouts(0, time.Hour)
+	lm.SetShutdownTimeout(20 * time.Millisecond)
+
+	started := time.Now()
+	err := lm.Start(context.Background())
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Start took %s, want the rollback bounded by the shutdown timeout", elapsed)
+	}
+
+	var lifecycleErr *LifecycleError
+	if !errors.As(err, &lifecycleErr) || lifecycleErr.Rollback == nil {
+		t.Fatalf("Start() = %v, want a *LifecycleError with a rollback report", err)
+	}
+	failed := lifecycleErr.Rollback.Failed()
+	if len(failed) != 1 || failed[0].Component != "database" || !errors.Is(failed[0].Error, context.DeadlineExceeded) {
+		t.Errorf("failed rollback entries = %+v, want database abandoned at the shutdown deadline", failed)
+	}
+	<-abandoned
+}
+
+func TestStopRunsLevelsDependentsFirst(t *testing.T) {
+	var mu sync.Mutex
+	var stopped []string
+	// Both components of the lowest level have to be stopping at once for either to return
+	var bothStopping sync.WaitGroup
+	bothStopping.Add(2)
+	component := func(name string, parallel bool, dependencies ...string) *testComponent {
+		return &testComponent{
+			name:         name,
+			dependencies: dependencies,
+			stop: func(ctx context.Context) error {
+				if parallel {
+					bothStopping.Done()
+					bothStopping.Wait()
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				stopped = append(stopped, name)
+				return nil
+			},
+		}
+	}
+	lm := newTestManager(t,
+		component("database", true),
+		component("cache", true),
+		component("repository", false, "database", "cache"),
+		component("api", false, "repository"),
+	)
+	lm.SetDefaultTimeouts(0, time.Second)
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	if err := lm.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v, want the components of a level to stop in parallel", err)
+	}
+
+	if len(stopped) != 4 || stopped[0] != "api" || stopped[1] != "repository" {
+		t.Errorf("stopped %v, want api, then repository, then database and cache", stopped)
+	}
+}
+
+func TestStopContinuesPastFailedLevel(t *testing.T) {
+	var databaseStopped bool
+	lm := newTestManager(t,
+		&testComponent{
+			name: "database",
+			stop: func(ctx context.Context) error {
+				databaseStopped = true
+				return nil
+			},
+		},
+		&testComponent{
+			name:         "api",
+			dependencies: []string{"database"},
+			stop:         func(ctx context.Context) error { return errors.New("drain failed") },
+		},
+	)
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	err := lm.Stop(context.Background())
+
+	var lifecycleErr *LifecycleError
+	if !errors.As(err, &lifecycleErr) {
+		t.Fatalf("Stop() = %v, want a *LifecycleError", err)
+	}
+	if lifecycleErr.Phase != PhaseShutdown || lifecycleErr.Level != 1 || len(lifecycleErr.Errors) != 1 || lifecycleErr.Errors["api"] == nil {
+		t.Errorf("Stop() = %v, want api failing at level 1", err)
+	}
+	if !databaseStopped {
+		t.Error("database was not stopped after api failed to stop")
+	}
+	if phase := lm.GetPhase(); phase != PhaseStopped {
+		t.Errorf("manager is %s after Stop, want stopped", phase)
+	}
+}
+
+func TestRollbackStopsStartedComponentsInReverseOrder(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	worker := recordingComponent("worker", &mu, &events, "database")
+	worker.start = func(ctx context.Context) error { return errors.New("queue unavailable") }
+	lm := newTestManager(t,
+		recordingComponent("database", &mu, &events),
+		recordingComponent("cache", &mu, &events),
+		recordingComponent("repository", &mu, &events, "database", "cache"),
+		worker,
+		recordingComponent("api", &mu, &events, "repository", "worker"),
+	)
+
+	err := lm.Start(context.Background())
+
+	var lifecycleErr *LifecycleError
+	if !errors.As(err, &lifecycleErr) || lifecycleErr.Rollback == nil {
+		t.Fatalf("Start() = %v, want a *LifecycleError with a rollback report", err)
+	}
+	var rolledBack []string
+	for _, entry := range lifecycleErr.Rollback.Entries {
+		if !entry.Stopped {
+			t.Errorf("%s was not stopped: %v", entry.Component, entry.Error)
+		}
+		rolledBack = append(rolledBack, fmt.Sprintf("%s@%d", entry.Component, entry.Level))
+	}
+	if want := "repository@1 cache@0 database@0"; strings.Join(rolledBack, " ") != want {
+		t.Errorf("rolled back %v, want %s", rolledBack, want)
+	}
+
+	// Only components that started are stopped, dependents before their dependencies
+	var stops []string
+	for _, event := range events {
+		if name, ok := strings.CutPrefix(event, "stop "); ok {
+			stops = append(stops, name)
+		}
+	}
+	if len(stops) != 3 || stops[0] != "repository" {
+		t.Errorf("stopped %v, want repository before database and cache", stops)
+	}
+	for name, state := range lm.GetAllComponentStates() {
+		if state.Phase != PhaseStopped {
+			t.Errorf("%s is %s after the rollback, want stopped", name, state.Phase)
+		}
+	}
+}
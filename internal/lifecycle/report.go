@@ -0,0 +1,161 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// DominanceThreshold is the share of the total startup time above which a component
+// is flagged as dominating the startup
+const DominanceThreshold = 0.3
+
+// StartupReport is the timing profile of a startup
+type StartupReport struct {
+	StartedAt    time.Time         `json:"started_at"`
+	Duration     time.Duration     `json:"duration_ns"` // Wall time of the whole startup
+	Succeeded    bool              `json:"succeeded"`
+	Levels       []LevelTiming     `json:"levels"`
+	Components   []ComponentTiming `json:"components"`
+	CriticalPath CriticalPath      `json:"critical_path"`
+	Dominant     []string          `json:"dominant,omitempty"` // Components at or above DominanceThreshold of the total
+}
+
+// LevelTiming is the timing of a single DAG level
+type LevelTiming struct {
+	Level      int           `json:"level"`
+	Duration   time.Duration `json:"duration_ns"` // Wall time until every component of the level finished
+	Components []string      `json:"components"`
+	Slowest    string        `json:"slowest"`
+}
+
+// ComponentTiming is the timing of a single component start
+type ComponentTiming struct {
+	Name     string        `json:"name"`
+	Level    int           `json:"level"`
+	Duration time.Duration `json:"duration_ns"`
+	Attempts int           `json:"attempts"`
+	Retries  int           `json:"retries"`
+	Share    float64       `json:"share"` // Fraction of the total startup duration
+	Error    string        `json:"error,omitempty"`
+}
+
+// CriticalPath is the longest chain of dependent components by start duration.
+// Speeding up any other component cannot shorten the startup below its duration.
+type CriticalPath struct {
+	Components []string      `json:"components"` // From the first dependency to the last dependent
+	Duration   time.Duration `json:"duration_ns"`
+}
+
+// JSON returns the report encoded as indented JSON
+func (r *StartupReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// GetStartupReport returns the timing profile of the last startup, or nil if Start was never called
+func (lm *DefaultLifecycleManager) GetStartupReport() *StartupReport {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+
+	return lm.startupReport
+}
+
+// buildStartupReport builds the startup report for the levels that were started so far.
+// The caller must hold the write lock.
+func (lm *DefaultLifecycleManager) buildStartupReport(startedAt time.Time, levels [][]*Node, levelDurations []time.Duration) *StartupReport {
+	lm.stateMu.Lock()
+	defer lm.stateMu.Unlock()
+
+	report := &StartupReport{
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+		Succeeded: true,
+	}
+
+	durations := make(map[string]time.Duration)
+	for levelIndex, duration := range levelDurations {
+		level := levels[levelIndex]
+		levelTiming := LevelTiming{
+			Level:    levelIndex,
+			Duration: duration,
+		}
+
+		for _, node := range level {
+			state := lm.states[node.Name]
+			timing := ComponentTiming{
+				Name:     node.Name,
+				Level:    levelIndex,
+				Duration: state.StartDuration,
+				Attempts: state.StartAttempts,
+			}
+			if timing.Attempts > 1 {
+				timing.Retries = timing.Attempts - 1
+			}
+			if report.Duration > 0 {
+				timing.Share = float64(timing.Duration) / float64(report.Duration)
+			}
+			if state.Error != nil {
+				timing.Error = state.Error.Error()
+				report.Succeeded = false
+			}
+
+			durations[node.Name] = timing.Duration
+			levelTiming.Components = append(levelTiming.Components, node.Name)
+			if levelTiming.Slowest == "" || timing.Duration > durations[levelTiming.Slowest] {
+				levelTiming.Slowest = node.Name
+			}
+			if timing.Share >= DominanceThreshold {
+				report.Dominant = append(report.Dominant, node.Name)
+			}
+			report.Components = append(report.Components, timing)
+		}
+
+		sort.Strings(levelTiming.Components)
+		report.Levels = append(report.Levels, levelTiming)
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Duration > report.Components[j].Duration
+	})
+	sort.Strings(report.Dominant)
+
+	report.CriticalPath = lm.criticalPath(levels[:len(levelDurations)], durations)
+
+	return report
+}
+
+// criticalPath finds the longest chain of dependencies weighted by start duration.
+// Levels are visited in order, so every dependency is finished before its dependents.
+func (lm *DefaultLifecycleManager) criticalPath(levels [][]*Node, durations map[string]time.Duration) CriticalPath {
+	finish := make(map[string]time.Duration)
+	previous := make(map[string]string)
+
+	var last string
+	for _, level := range levels {
+		for _, node := range level {
+			var longest time.Duration
+			for _, dep := range node.Dependencies {
+				if finish[dep] > longest || (finish[dep] == longest && previous[node.Name] == "") {
+					longest = finish[dep]
+					previous[node.Name] = dep
+				}
+			}
+			finish[node.Name] = longest + durations[node.Name]
+
+			if last == "" || finish[node.Name] > finish[last] {
+				last = node.Name
+			}
+		}
+	}
+
+	if last == "" {
+		return CriticalPath{}
+	}
+
+	path := CriticalPath{Duration: finish[last]}
+	for name := last; name != ""; name = previous[name] {
+		path.Components = append([]string{name}, path.Components...)
+	}
+
+	return path
+}
@@ -0,0 +1,130 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCriticalPathFollowsLongestChain(t *testing.T) {
+	node := func(name string, dependencies ...string) *Node {
+		return &Node{Name: name, Dependencies: dependencies}
+	}
+	levels := [][]*Node{
+		{node("config"), node("database")},
+		{node("cache", "config", "database"), node("migrations", "config")},
+		{node("api", "cache")},
+	}
+	durations := map[string]time.Duration{
+		"config":     10 * time.Millisecond,
+		"database":   50 * time.Millisecond,
+		"cache":      5 * time.Millisecond,
+		"migrations": 100 * time.Millisecond,
+		"api":        1 * time.Millisecond,
+	}
+
+	path := (&DefaultLifecycleManager{}).criticalPath(levels, durations)
+
+	// The slow migrations outweigh the longer chain through the database
+	if !reflect.DeepEqual(path.Components, []string{"config", "migrations"}) || path.Duration != 110*time.Millisecond {
+		t.Errorf("critical path = %v (%s), want [config migrations] (110ms)", path.Components, path.Duration)
+	}
+
+	durations["migrations"] = time.Millisecond
+	path = (&DefaultLifecycleManager{}).criticalPath(levels, durations)
+	if !reflect.DeepEqual(path.Components, []string{"database", "cache", "api"}) || path.Duration != 56*time.Millisecond {
+		t.Errorf("critical path = %v (%s), want [database cache api] (56ms)", path.Components, path.Duration)
+	}
+}
+
+func TestStartupReport(t *testing.T) {
+	sleep := func(duration time.Duration) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			time.Sleep(duration)
+			return nil
+		}
+	}
+	var attempts atomic.Int32
+	lm := newTestManager(t,
+		&testComponent{name: "database", start: sleep(60 * time.Millisecond)},
+		&testComponent{
+			name: "cache",
+			start: func(ctx context.Context) error {
+				if attempts.Add(1) == 1 {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			retry: &RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1},
+		},
+		&testComponent{name: "api", dependencies: []string{"database", "cache"}, start: sleep(20 * time.Millisecond)},
+	)
+	if report := lm.GetStartupReport(); report != nil {
+		t.Fatalf("GetStartupReport() = %+v before Start, want nil", report)
+	}
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	report := lm.GetStartupReport()
+
+	if !report.Succeeded || len(report.Levels) != 2 {
+		t.Fatalf("report = %+v, want a successful startup over 2 levels", report)
+	}
+	if level := report.Levels[0]; !reflect.DeepEqual(level.Components, []string{"cache", "database"}) || level.Slowest != "database" {
+		t.Errorf("level 0 = %+v, want cache and database with database slowest", level)
+	}
+	if report.Components[0].Name != "database" {
+		t.Errorf("slowest component is %s, want components sorted by duration", report.Components[0].Name)
+	}
+	for _, timing := range report.Components {
+		if timing.Name == "cache" && (timing.Attempts != 2 || timing.Retries != 1) {
+			t.Errorf("cache timing = %+v, want 2 attempts and 1 retry", timing)
+		}
+	}
+	if !reflect.DeepEqual(report.CriticalPath.Components, []string{"database", "api"}) {
+		t.Errorf("critical path = %v, want [database api]", report.CriticalPath.Components)
+	}
+	if !reflect.DeepEqual(report.Dominant, []string{"database"}) {
+		t.Errorf("dominant components = %v, want [database]", report.Dominant)
+	}
+
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON() = %v", err)
+	}
+	var decoded StartupReport
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.CriticalPath.Duration != report.CriticalPath.Duration {
+		t.Errorf("JSON() decoded to %+v (%v), want the report", decoded, err)
+	}
+}
+
+func TestStartupReportOfFailedStartup(t *testing.T) {
+	lm := newTestManager(t,
+		&testComponent{name: "database"},
+		&testComponent{
+			name:         "api",
+			dependencies: []string{"database"},
+			start:        func(ctx context.Context) error { return errors.New("port in use") },
+		},
+		&testComponent{name: "worker", dependencies: []string{"api"}},
+	)
+	if err := lm.Start(context.Background()); err == nil {
+		t.Fatal("Start() succeeded, want the api to fail")
+	}
+
+	report := lm.GetStartupReport()
+	if report == nil || report.Succeeded || len(report.Levels) != 2 {
+		t.Fatalf("report = %+v, want a failed startup reporting the 2 levels started", report)
+	}
+	for _, timing := range report.Components {
+		if timing.Name == "api" && timing.Error == "" {
+			t.Errorf("api timing = %+v, want its error", timing)
+		}
+	}
+}
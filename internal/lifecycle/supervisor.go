@@ -0,0 +1,318 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RestartComponent stops and restarts a component together with its dependents
+func (lm *DefaultLifecycleManager) RestartComponent(ctx context.Context, name string, reason string) error {
+	ctx, done := lm.beginRestarts(ctx)
+	defer done()
+
+	lm.mu.RLock()
+	phase := lm.phase
+	_, exists := lm.states[name]
+	lm.mu.RUnlock()
+
+	if phase != PhaseRunning {
+		return fmt.Errorf("cannot restart component %s: lifecycle manager is not running (current: %s)", name, phase)
+	}
+
+	if !exists {
+		return fmt.Errorf("component %s is not registered", name)
+	}
+
+	return lm.restartComponent(ctx, name, reason)
+}
+
+// superviseComponents restarts failed components according to their restart policies.
+// The components are collected under the lock, which is not held while they restart.
+func (lm *DefaultLifecycleManager) superviseComponents(ctx context.Context) {
+	ctx, done := lm.beginRestarts(ctx)
+	defer done()
+
+	// The DAG traversal mutates visit flags, so it needs the write lock
+	lm.mu.Lock()
+	if lm.phase != PhaseRunning {
+		lm.mu.Unlock()
+		return
+	}
+	startupOrder, err := lm.dag.GetStartupOrder()
+	states := make(map[string]*ComponentState, len(lm.states))
+	for name, state := range lm.states {
+		states[name] = state
+	}
+	lm.mu.Unlock()
+
+	if err != nil {
+		if lm.logger != nil {
+			lm.logger.Error("Failed to determine component order for supervision",
+				"error", err.Error(),
+			)
+		}
+		return
+	}
+
+	// Walk components in dependency order so that a failed dependency is restarted
+	// (together with its dependents) before any of its dependents are considered
+	for _, node := range startupOrder {
+		// Restarts run without the lock, so the manager may have stopped in the meantime
+		if ctx.Err() != nil || lm.GetPhase() != PhaseRunning {
+			return
+		}
+
+		config := restartConfigOf(node.Component)
+		if config == nil || config.Policy == RestartNever {
+			continue
+		}
+
+		state, exists := states[node.Name]
+		if !exists {
+			continue
+		}
+
+		lm.stateMu.Lock()
+		reason := restartReason(state, config.Policy)
+		skip := reason == "" || state.RestartsExhausted
+		limitReached := !skip && config.MaxRestarts > 0 && countRestartsSince(state, config.Window) >= config.MaxRestarts
+		if limitReached {
+			state.RestartsExhausted = true
+		}
+		lm.stateMu.Unlock()
+
+		if skip {
+			continue
+		}
+
+		if limitReached {
+			if lm.logger != nil {
+				lm.logger.Error("Component restart limit reached, giving up",
+					"component", node.Name,
+					"max_restarts", config.MaxRestarts,
+					"window", config.Window,
+				)
+			}
+			continue
+		}
+
+		if err := lm.restartComponent(ctx, node.Name, reason); err != nil && lm.logger != nil {
+			lm.logger.Error("Automatic component restart failed",
+				"component", node.Name,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// beginRestarts serializes restarts, since they run without the lock while components restart, and returns
+// a context that Stop cancels so that shutdown doesn't wait for backoffs. The returned function ends the restarts.
+func (lm *DefaultLifecycleManager) beginRestarts(ctx context.Context) (context.Context, func()) {
+	lm.restartMu.Lock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	lm.mu.Lock()
+	lm.restartCancel = cancel
+	lm.mu.Unlock()
+
+	return ctx, func() {
+		lm.mu.Lock()
+		lm.restartCancel = nil
+		lm.mu.Unlock()
+
+		cancel()
+		lm.restartMu.Unlock()
+	}
+}
+
+// cancelRestarts cancels a running restart and waits for it to end.
+// The caller must not hold the lock.
+func (lm *DefaultLifecycleManager) cancelRestarts() {
+	lm.mu.Lock()
+	if lm.restartCancel != nil {
+		lm.restartCancel()
+	}
+	lm.mu.Unlock()
+
+	lm.restartMu.Lock()
+	defer lm.restartMu.Unlock()
+}
+
+// restartComponent stops a component and all of its transitive dependents in reverse
+// dependency order, resets them so they get fresh instances, and starts them again.
+// The components and their states are collected under the lock, which is released before
+// they stop, reset and start. The caller must not hold the lock.
+func (lm *DefaultLifecycleManager) restartComponent(ctx context.Context, name string, reason string) error {
+	lm.mu.Lock()
+	startupOrder, err := lm.dag.GetStartupOrder()
+	if err != nil {
+		lm.mu.Unlock()
+		return fmt.Errorf("failed to determine restart order: %w", err)
+	}
+
+	// Collect the component and everything that transitively depends on it
+	affected := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range lm.dag.GetDependents(current) {
+			if !affected[dependent] {
+				affected[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	var nodes []*Node
+	states := make(map[string]*ComponentState)
+	for _, node := range startupOrder {
+		if state, exists := lm.states[node.Name]; exists && affected[node.Name] {
+			nodes = append(nodes, node)
+			states[node.Name] = state
+		}
+	}
+	lm.mu.Unlock()
+
+	if lm.logger != nil {
+		lm.logger.Warn("Restarting component",
+			"component", name,
+			"reason", reason,
+			"affected", len(nodes),
+		)
+	}
+
+	// Stop dependents before their dependencies
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		if err := lm.stopComponent(ctx, node, states[node.Name]); err != nil && lm.logger != nil {
+			lm.logger.Warn("Failed to stop component during restart",
+				"component", node.Name,
+				"error", err.Error(),
+			)
+		}
+
+		if resettable, ok := node.Component.(ResettableComponent); ok {
+			if err := resettable.Reset(ctx); err != nil && lm.logger != nil {
+				lm.logger.Warn("Failed to reset component during restart",
+					"component", node.Name,
+					"error", err.Error(),
+				)
+			}
+		}
+	}
+
+	// Start dependencies before their dependents
+	for _, node := range nodes {
+		state := states[node.Name]
+		if phase := lm.GetPhase(); phase != PhaseRunning {
+			return fmt.Errorf("failed to restart component %s: lifecycle manager is no longer running (current: %s)", node.Name, phase)
+		}
+		if !lm.isRegistered(node.Name, state) {
+			// Unregistered while restarting
+			continue
+		}
+
+		record := RestartRecord{
+			Timestamp: time.Now(),
+			Reason:    reason,
+			Cause:     name,
+		}
+		if node.Name != name {
+			record.Reason = fmt.Sprintf("dependency %s restarted", name)
+		}
+
+		startErr := lm.startComponentWithBackoff(ctx, node, state)
+		record.Error = startErr
+
+		lm.stateMu.Lock()
+		state.Restarts = append(state.Restarts, record)
+		if startErr == nil {
+			state.RestartsExhausted = false
+		}
+		lm.stateMu.Unlock()
+
+		if lm.metrics != nil {
+			lm.metrics.RecordComponentRestart(node.Name)
+		}
+
+		if startErr != nil {
+			// Leave the remaining dependents stopped; they are restarted once their
+			// dependency is running again
+			return fmt.Errorf("failed to restart component %s: %w", node.Name, startErr)
+		}
+	}
+
+	if lm.logger != nil {
+		lm.logger.Info("Component restarted successfully",
+			"component", name,
+		)
+	}
+
+	return nil
+}
+
+// startComponentWithBackoff starts a component, retrying with the backoff from its restart configuration.
+// Each attempt is only made while the manager is still running.
+func (lm *DefaultLifecycleManager) startComponentWithBackoff(ctx context.Context, node *Node, state *ComponentState) error {
+	config := restartConfigOf(node.Component)
+	if config == nil || config.Backoff == nil {
+		return lm.startComponent(ctx, node, state)
+	}
+
+	return RetryWithBackoff(ctx, *config.Backoff, func() error {
+		if phase := lm.GetPhase(); phase != PhaseRunning {
+			return fmt.Errorf("lifecycle manager is no longer running (current: %s)", phase)
+		}
+		return lm.startComponent(ctx, node, state)
+	})
+}
+
+// isRegistered reports whether the component with the given state is still registered under name
+func (lm *DefaultLifecycleManager) isRegistered(name string, state *ComponentState) bool {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
+
+	return lm.states[name] == state
+}
+
+// restartConfigOf returns the restart configuration of a component, if it has one
+func restartConfigOf(component Component) *RestartConfig {
+	if restartable, ok := component.(RestartableComponent); ok {
+		return restartable.GetRestartConfig()
+	}
+	return nil
+}
+
+// restartReason returns why a component needs restarting under the given policy,
+// or an empty string if it doesn't. The caller must hold the state lock.
+func restartReason(state *ComponentState, policy RestartPolicy) string {
+	switch {
+	case state.Phase == PhaseRunning && state.Health.Status == HealthStatusUnhealthy:
+		return "component reported unhealthy: " + state.Health.Message
+	case state.Phase == PhaseStopped && state.Error != nil:
+		// Only a failed start counts as a failure; StopError records a failed stop
+		return "component failed: " + state.Error.Error()
+	case state.Phase == PhaseStopped && policy == RestartAlways:
+		return "component is not running"
+	default:
+		return ""
+	}
+}
+
+// countRestartsSince counts the restarts initiated by a component's own failure within the window.
+// The caller must hold the state lock.
+func countRestartsSince(state *ComponentState, window time.Duration) int {
+	count := 0
+	for _, record := range state.Restarts {
+		if record.Cause != state.Name {
+			continue
+		}
+		if window > 0 && time.Since(record.Timestamp) > window {
+			continue
+		}
+		count++
+	}
+	return count
+}
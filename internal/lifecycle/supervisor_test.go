@@ -0,0 +1,339 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingComponent returns a component that tracks how many of its instances are running
+func countingComponent(name string, running *atomic.Int32, dependencies ...string) *testComponent {
+	return &testComponent{
+		name:         name,
+		dependencies: dependencies,
+		start: func(ctx context.Context) error {
+			running.Add(1)
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+		stop: func(ctx context.Context) error {
+			running.Add(-1)
+			return nil
+		},
+	}
+}
+
+func TestRestartDoesNotBlockReaders(t *testing.T) {
+	release := make(chan struct{})
+	var restarting atomic.Bool
+	lm := newTestManager(t, &testComponent{
+		name: "cache",
+		start: func(ctx context.Context) error {
+			if restarting.Load() {
+				<-release
+			}
+			return nil
+		},
+	})
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	restarting.Store(true)
+	done := make(chan error, 1)
+	go func() { done <- lm.RestartComponent(context.Background(), "cache", "test") }()
+
+	// Wait for the restart to reach the component's Start
+	deadline := time.Now().Add(time.Second)
+	for {
+		if state, _ := lm.GetComponentState("cache"); state.Phase == PhaseStartup {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("restart did not start the component again")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Readers and health checks don't wait for the restarting component
+	lm.GetAllComponentStates()
+	lm.HealthCheck(context.Background())
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("RestartComponent() = %v", err)
+	}
+	if state, _ := lm.GetComponentState("cache"); state.Phase != PhaseRunning || len(state.Restarts) != 1 {
+		t.Errorf("cache is %s with %d restarts, want running with 1 restart", state.Phase, len(state.Restarts))
+	}
+}
+
+func TestRestartRacingWithStop(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		var running atomic.Int32
+		lm := newTestManager(t,
+			countingComponent("database", &running),
+			countingComponent("api", &running, "database"),
+		)
+		if err := lm.Start(context.Background()); err != nil {
+			t.Fatalf("Start() = %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for _, name := range []string{"database", "api"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				// Restarts fail once the manager is stopping, which ends the loop
+				for {
+					if err := lm.RestartComponent(context.Background(), name, "test"); err != nil {
+						return
+					}
+				}
+			}(name)
+		}
+
+		time.Sleep(2 * time.Millisecond)
+		if err := lm.Stop(context.Background()); err != nil {
+			t.Fatalf("Stop() = %v", err)
+		}
+		wg.Wait()
+
+		if n := running.Load(); n != 0 {
+			t.Fatalf("%d component instances still running after Stop", n)
+		}
+		for name, state := range lm.GetAllComponentStates() {
+			if state.Phase != PhaseStopped {
+				t.Errorf("%s is %s after Stop, want stopped", name, state.Phase)
+			}
+		}
+	}
+}
+
+func TestRestartRacingWithHealthMonitor(t *testing.T) {
+	var running atomic.Int32
+	var unhealthy atomic.Bool
+	flaky := countingComponent("flaky", &running)
+	flaky.health = func(ctx context.Context) ComponentHealth {
+		if unhealthy.Swap(false) {
+			return ComponentHealth{Status: HealthStatusUnhealthy, Message: "lost connection"}
+		}
+		return ComponentHealth{Status: HealthStatusHealthy}
+	}
+	config := DefaultRestartConfig(RestartOnFailure)
+	config.MaxRestarts = 0
+	config.Backoff = nil
+	flaky.restart = &config
+
+	lm := newTestManager(t, flaky, countingComponent("consumer", &running, "flaky"))
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := lm.StartHealthMonitor(time.Millisecond); err != nil {
+		t.Fatalf("StartHealthMonitor() = %v", err)
+	}
+
+	// Restart manually while the monitor checks health and restarts the flaky component
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		unhealthy.Store(true)
+		if err := lm.RestartComponent(context.Background(), "consumer", "test"); err != nil {
+			t.Fatalf("RestartComponent() = %v", err)
+		}
+		lm.GetAllComponentStates()
+	}
+
+	if err := lm.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if n := running.Load(); n != 0 {
+		t.Errorf("%d component instances still running after Stop", n)
+	}
+	if state, _ := lm.GetComponentState("flaky"); len(state.Restarts) == 0 {
+		t.Error("health monitor never restarted the unhealthy component")
+	}
+}
+
+// recordingComponent returns a component that appends its start and stop calls to events
+func recordingComponent(name string, mu *sync.Mutex, events *[]string, dependencies ...string) *testComponent {
+	record := func(event string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*events = append(*events, event+" "+name)
+			return nil
+		}
+	}
+	return &testComponent{
+		name:         name,
+		dependencies: dependencies,
+		start:        record("start"),
+		stop:         record("stop"),
+	}
+}
+
+// restartOnFailure returns a restart configuration without backoff allowing maxRestarts within window
+func restartOnFailure(maxRestarts int, window time.Duration) *RestartConfig {
+	config := DefaultRestartConfig(RestartOnFailure)
+	config.MaxRestarts = maxRestarts
+	config.Window = window
+	config.Backoff = nil
+	return &config
+}
+
+func TestSupervisorRestartsUnhealthyComponentWithDependents(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	var unhealthy atomic.Bool
+	database := recordingComponent("database", &mu, &events)
+	database.health = func(ctx context.Context) ComponentHealth {
+		if unhealthy.Load() {
+			return ComponentHealth{Status: HealthStatusUnhealthy, Message: "lost connection"}
+		}
+		return ComponentHealth{Status: HealthStatusHealthy}
+	}
+	database.restart = restartOnFailure(0, 0)
+	lm := newTestManager(t, database, recordingComponent("api", &mu, &events, "database"))
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	unhealthy.Store(true)
+	lm.HealthCheck(context.Background())
+	unhealthy.Store(false)
+	events = nil
+	lm.superviseComponents(context.Background())
+
+	want := []string{"stop api", "stop database", "start database", "start api"}
+	if strings.Join(events, ", ") != strings.Join(want, ", ") {
+		t.Errorf("restart ran %v, want %v", events, want)
+	}
+	state, _ := lm.GetComponentState("api")
+	if len(state.Restarts) != 1 || state.Restarts[0].Cause != "database" || state.Restarts[0].Reason != "dependency database restarted" {
+		t.Errorf("api restarts = %+v, want one caused by database", state.Restarts)
+	}
+}
+
+func TestSupervisorGivesUpAfterMaxRestarts(t *testing.T) {
+	flaky := &testComponent{
+		name: "flaky",
+		health: func(ctx context.Context) ComponentHealth {
+			return ComponentHealth{Status: HealthStatusUnhealthy, Message: "still broken"}
+		},
+		restart: restartOnFailure(2, time.Hour),
+	}
+	lm := newTestManager(t, flaky)
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	for i := 0; i < 4; i++ {
+		lm.HealthCheck(context.Background())
+		lm.superviseComponents(context.Background())
+	}
+
+	state, _ := lm.GetComponentState("flaky")
+	if len(state.Restarts) != 2 || !state.RestartsExhausted {
+		t.Errorf("flaky restarted %d times (exhausted %v), want 2 restarts and then giving up", len(state.Restarts), state.RestartsExhausted)
+	}
+}
+
+func TestSupervisorOnlyCountsRestartsWithinWindow(t *testing.T) {
+	flaky := &testComponent{
+		name: "flaky",
+		health: func(ctx context.Context) ComponentHealth {
+			return ComponentHealth{Status: HealthStatusUnhealthy, Message: "still broken"}
+		},
+		restart: restartOnFailure(1, 5*time.Millisecond),
+	}
+	lm := newTestManager(t, flaky)
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	for i := 0; i < 3; i++ {
+		lm.HealthCheck(context.Background())
+		lm.superviseComponents(context.Background())
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if state, _ := lm.GetComponentState("flaky"); len(state.Restarts) != 3 || state.RestartsExhausted {
+		t.Errorf("flaky restarted %d times (exhausted %v), want a restart once each earlier one left the window", len(state.Restarts), state.RestartsExhausted)
+	}
+}
+
+func TestSupervisorIgnoresRestartNever(t *testing.T) {
+	config := DefaultRestartConfig(RestartNever)
+	lm := newTestManager(t, &testComponent{
+		name: "fragile",
+		health: func(ctx context.Context) ComponentHealth {
+			return ComponentHealth{Status: HealthStatusUnhealthy}
+		},
+		restart: &config,
+	})
+	if err := lm.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+	lm.HealthCheck(context.Background())
+	lm.superviseComponents(context.Background())
+
+	if state, _ := lm.GetComponentState("fragile"); len(state.Restarts) != 0 {
+		t.Errorf("component with RestartNever restarted %d times", len(state.Restarts))
+	}
+}
+
+func TestRestartRetriesStartWithBackoff(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		failures int32
+		wantErr  bool
+	}{
+		{"recovers", 2, false},
+		{"exhausts attempts", 3, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var restarting atomic.Bool
+			var attempts atomic.Int32
+			config := DefaultRestartConfig(RestartOnFailure)
+			config.Backoff = &RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 2}
+			lm := newTestManager(t, &testComponent{
+				name: "cache",
+				start: func(ctx context.Context) error {
+					if restarting.Load() && attempts.Add(1) <= test.failures {
+						return errors.New("connection refused")
+					}
+					return nil
+				},
+				restart: &config,
+			})
+			if err := lm.Start(context.Background()); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			t.Cleanup(func() { _ = lm.Stop(context.Background()) })
+
+			restarting.Store(true)
+			err := lm.RestartComponent(context.Background(), "cache", "test")
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RestartComponent() = %v, want error %v", err, test.wantErr)
+			}
+			if n := attempts.Load(); n != 3 {
+				t.Errorf("start was attempted %d times, want 3", n)
+			}
+			state, _ := lm.GetComponentState("cache")
+			if len(state.Restarts) != 1 || (state.Restarts[0].Error != nil) != test.wantErr {
+				t.Errorf("restarts = %+v, want one recording the outcome", state.Restarts)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
 	"time"
+
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
 )
 
 // RetryConfig configures retry behavior for component operations
@@ -164,15 +167,134 @@ const (
 	HealthStatusUnknown   HealthStatus = "unknown"
 )
 
+// HealthTransition describes a change in a component's health status
+type HealthTransition struct {
+	Component string
+	Previous  ComponentHealth
+	Current   ComponentHealth
+	Timestamp time.Time
+}
+
+// HealthTransitionHandler is called whenever a component's health status changes
+type HealthTransitionHandler func(ctx context.Context, transition HealthTransition)
+
+// RestartPolicy determines when a failed component is restarted automatically.
+// The policies are modeled on Erlang supervisor restart types.
+type RestartPolicy string
+
+const (
+	// RestartNever never restarts the component automatically (Erlang "temporary")
+	RestartNever RestartPolicy = "never"
+	// RestartOnFailure restarts the component when it reports unhealthy or its start failed (Erlang "transient")
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartAlways restarts the component whenever it is not running while the manager is running (Erlang "permanent")
+	RestartAlways RestartPolicy = "always"
+)
+
+// RestartConfig configures automatic restarts of a component
+type RestartConfig struct {
+	Policy      RestartPolicy // When the component should be restarted
+	MaxRestarts int           // Maximum restarts allowed within Window (0 means unlimited)
+	Window      time.Duration // Time window used to count restarts (0 means since registration)
+	Backoff     *RetryConfig  // Retry behavior for starting the component again (nil means a single attempt)
+}
+
+// DefaultRestartConfig returns a default restart configuration for the given policy
+func DefaultRestartConfig(policy RestartPolicy) RestartConfig {
+	backoff := DefaultRetryConfig()
+	return RestartConfig{
+		Policy:      policy,
+		MaxRestarts: 3,
+		Window:      time.Minute,
+		Backoff:     &backoff,
+	}
+}
+
+// RestartRecord records a restart of a component
+type RestartRecord struct {
+	Timestamp time.Time
+	Reason    string
+	Cause     string // Name of the component whose failure triggered the restart
+	Error     error  // Error returned when starting the component again, if any
+}
+
+// RestartableComponent is implemented by components that provide a restart policy
+type RestartableComponent interface {
+	Component
+
+	// GetRestartConfig returns the restart configuration for this component (nil means never restart)
+	GetRestartConfig() *RestartConfig
+}
+
+// ResettableComponent is implemented by components that can discard their current instance,
+// so that the next Start works with a fresh one
+type ResettableComponent interface {
+	Component
+
+	// Reset discards the component's current instance
+	Reset(ctx context.Context) error
+}
+
+// TimeoutComponent is implemented by components that override the default start and stop timeouts
+type TimeoutComponent interface {
+	Component
+
+	// GetStartTimeout returns the maximum duration of a single Start call (0 means use the default)
+	GetStartTimeout() time.Duration
+
+	// GetStopTimeout returns the maximum duration of a single Stop call (0 means use the default)
+	GetStopTimeout() time.Duration
+}
+
+// TimeoutError is returned when a component's Start or Stop call overruns its timeout
+type TimeoutError struct {
+	Component string
+	Operation string // "start" or "stop"
+	Timeout   time.Duration
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("component %s %s timed out after %s", e.Component, e.Operation, e.Timeout)
+}
+
+// Unwrap returns context.DeadlineExceeded so that errors.Is works with timeout errors
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
 // ComponentState represents the current state of a component
 type ComponentState struct {
-	Name         string
-	Phase        Phase
-	Health       ComponentHealth
-	StartedAt    *time.Time
-	StoppedAt    *time.Time
-	Dependencies []string
-	Error        error
+	Name              string
+	Phase             Phase
+	Health            ComponentHealth
+	StartedAt         *time.Time
+	StoppedAt         *time.Time
+	StartDuration     time.Duration // Time taken by the last start, including retries
+	StopDuration      time.Duration // Time taken by the last stop, including retries
+	StartAttempts     int           // Number of attempts made by the last start
+	StopAttempts      int           // Number of attempts made by the last stop
+	Dependencies      []string
+	Error             error // Error of the last failed start, cleared once the component starts
+	StopError         error // Error of the last stop, kept apart since a failed stop doesn't call for a restart
+	Restarts          []RestartRecord
+	RestartsExhausted bool // Set when the restart limit was reached and supervision gave up
+}
+
+// MetricsRecorder receives lifecycle metrics from the lifecycle manager.
+// Implementations must be safe for concurrent use, since components start and stop in parallel.
+type MetricsRecorder interface {
+	// RecordComponentStart records a component start with its duration and number of attempts
+	RecordComponentStart(name string, duration time.Duration, attempts int, err error)
+
+	// RecordComponentStop records a component stop with its duration and number of attempts
+	RecordComponentStop(name string, duration time.Duration, attempts int, err error)
+
+	// RecordComponentRestart records a component restart
+	RecordComponentRestart(name string)
+
+	// RecordComponentHealth records the latest health status of a component
+	RecordComponentHealth(name string, status HealthStatus)
 }
 
 // LifecycleManager manages the lifecycle of components
@@ -183,6 +305,15 @@ type LifecycleManager interface {
 	// UnregisterComponent removes a component from lifecycle management
 	UnregisterComponent(name string) error
 
+	// StartComponent starts a single component while the lifecycle manager is running
+	StartComponent(ctx context.Context, name string) error
+
+	// StopComponent stops a single component while the lifecycle manager is running
+	StopComponent(ctx context.Context, name string) error
+
+	// GetDependents returns the names of the components that depend on the given component
+	GetDependents(name string) []string
+
 	// Start starts all components in dependency order
 	Start(ctx context.Context) error
 
@@ -204,8 +335,38 @@ type LifecycleManager interface {
 	// GetPhase returns the current lifecycle phase
 	GetPhase() Phase
 
+	// GetStartupReport returns the timing profile of the last startup, or nil if Start was never called
+	GetStartupReport() *StartupReport
+
+	// SetDefaultTimeouts sets the start and stop timeouts used for components that don't specify their own
+	SetDefaultTimeouts(startTimeout, stopTimeout time.Duration)
+
+	// SetStartupTimeout sets how long Start waits for all components to start
+	SetStartupTimeout(timeout time.Duration)
+
+	// SetShutdownTimeout sets how long the rollback of a failed startup waits for components to stop
+	SetShutdownTimeout(timeout time.Duration)
+
+	// SetMetricsRecorder sets the recorder that receives lifecycle metrics
+	SetMetricsRecorder(recorder MetricsRecorder)
+
+	// SetTracer sets the tracer used to create spans for startup, shutdown and component operations
+	SetTracer(tracer tracing.Tracer)
+
+	// RestartComponent stops and restarts a component together with its dependents
+	RestartComponent(ctx context.Context, name string, reason string) error
+
 	// HealthCheck performs a health check on all components
 	HealthCheck(ctx context.Context) map[string]ComponentHealth
+
+	// AddHealthTransitionHandler registers a handler called when a component's health status changes
+	AddHealthTransitionHandler(handler HealthTransitionHandler)
+
+	// StartHealthMonitor starts a background loop that health checks all components on the given interval
+	StartHealthMonitor(interval time.Duration) error
+
+	// StopHealthMonitor stops the background health monitor and waits for it to exit
+	StopHealthMonitor()
 }
 
 // ComponentOption provides options for component configuration
@@ -0,0 +1,254 @@
+package metrics
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AnasImloul/go-orchestrator/internal/di"
+	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
+)
+
+// DefaultBuckets are the default latency histogram bucket upper bounds in seconds
+var DefaultBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// Collector is an in-memory metrics backend. It implements di.MetricsProvider for container
+// metrics and lifecycle.MetricsRecorder for component lifecycle metrics.
+type Collector struct {
+	buckets       []float64
+	resolutions   map[string]*resolutionMetrics
+	registrations map[registrationKey]int64
+	components    map[string]*ComponentMetrics
+	mu            sync.RWMutex
+}
+
+// Histogram is a cumulative latency histogram
+type Histogram struct {
+	Buckets []float64 // Upper bounds in seconds
+	Counts  []uint64  // Cumulative number of observations at or below each bound
+	Count   uint64
+	Sum     float64 // Sum of all observations in seconds
+}
+
+// ResolutionMetrics holds the resolution metrics of a single service type
+type ResolutionMetrics struct {
+	Type      string
+	Successes uint64
+	Failures  uint64
+	Latency   Histogram
+}
+
+// ComponentMetrics holds the lifecycle metrics of a single component
+type ComponentMetrics struct {
+	Name          string
+	Starts        uint64
+	StartFailures uint64
+	Stops         uint64
+	StopFailures  uint64
+	Restarts      uint64
+	StartDuration time.Duration // Duration of the last start
+	StopDuration  time.Duration // Duration of the last stop
+	StartAttempts int           // Attempts made by the last start
+	StopAttempts  int           // Attempts made by the last stop
+	Health        lifecycle.HealthStatus
+}
+
+type resolutionMetrics struct {
+	successes uint64
+	failures  uint64
+	counts    []uint64 // Non-cumulative counts per bucket, with a final +Inf bucket
+	sum       float64
+}
+
+type registrationKey struct {
+	serviceType string
+	lifetime    string
+}
+
+// NewCollector creates a new in-memory metrics collector. If no buckets are given, DefaultBuckets are used.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+
+	return &Collector{
+		buckets:       sorted,
+		resolutions:   make(map[string]*resolutionMetrics),
+		registrations: make(map[registrationKey]int64),
+		components:    make(map[string]*ComponentMetrics),
+	}
+}
+
+// RecordResolution records a service resolution
+func (c *Collector) RecordResolution(serviceType reflect.Type, duration int64, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := typeName(serviceType)
+	metrics, exists := c.resolutions[key]
+	if !exists {
+		metrics = &resolutionMetrics{counts: make([]uint64, len(c.buckets)+1)}
+		c.resolutions[key] = metrics
+	}
+
+	if success {
+		metrics.successes++
+	} else {
+		metrics.failures++
+	}
+
+	seconds := time.Duration(duration).Seconds()
+	metrics.sum += seconds
+	bucket := sort.SearchFloat64s(c.buckets, seconds)
+	metrics.counts[bucket]++
+}
+
+// RecordRegistration records a service registration
+func (c *Collector) RecordRegistration(serviceType reflect.Type, lifetime di.ServiceLifetime) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.registrations[registrationKey{serviceType: typeName(serviceType), lifetime: lifetime.String()}]++
+}
+
+// RecordComponentStart records a component start with its duration and number of attempts
+func (c *Collector) RecordComponentStart(name string, duration time.Duration, attempts int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	component := c.component(name)
+	component.Starts++
+	if err != nil {
+		component.StartFailures++
+	}
+	component.StartDuration = duration
+	component.StartAttempts = attempts
+}
+
+// RecordComponentStop records a component stop with its duration and number of attempts
+func (c *Collector) RecordComponentStop(name string, duration time.Duration, attempts int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	component := c.component(name)
+	component.Stops++
+	if err != nil {
+		component.StopFailures++
+	}
+	component.StopDuration = duration
+	component.StopAttempts = attempts
+}
+
+// RecordComponentRestart records a component restart
+func (c *Collector) RecordComponentRestart(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.component(name).Restarts++
+}
+
+// RecordComponentHealth records the latest health status of a component
+func (c *Collector) RecordComponentHealth(name string, status lifecycle.HealthStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.component(name).Health = status
+}
+
+// Resolutions returns the resolution metrics of every resolved service type, sorted by type
+func (c *Collector) Resolutions() []ResolutionMetrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]ResolutionMetrics, 0, len(c.resolutions))
+	for serviceType, metrics := range c.resolutions {
+		histogram := Histogram{
+			Buckets: append([]float64{}, c.buckets...),
+			Counts:  make([]uint64, len(c.buckets)),
+			Sum:     metrics.sum,
+		}
+		var cumulative uint64
+		for i := range c.buckets {
+			cumulative += metrics.counts[i]
+			histogram.Counts[i] = cumulative
+		}
+		histogram.Count = cumulative + metrics.counts[len(c.buckets)]
+
+		result = append(result, ResolutionMetrics{
+			Type:      serviceType,
+			Successes: metrics.successes,
+			Failures:  metrics.failures,
+			Latency:   histogram,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+	return result
+}
+
+// Components returns the lifecycle metrics of every component, sorted by name
+func (c *Collector) Components() []ComponentMetrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]ComponentMetrics, 0, len(c.components))
+	for _, component := range c.components {
+		result = append(result, *component)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// GetMetrics returns a snapshot of all metrics
+func (c *Collector) GetMetrics() map[string]interface{} {
+	c.mu.RLock()
+	registrations := make(map[string]int64, len(c.registrations))
+	for key, count := range c.registrations {
+		registrations[key.serviceType+" ("+key.lifetime+")"] = count
+	}
+	c.mu.RUnlock()
+
+	return map[string]interface{}{
+		"resolutions":   c.Resolutions(),
+		"registrations": registrations,
+		"components":    c.Components(),
+	}
+}
+
+// Reset discards all recorded metrics
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resolutions = make(map[string]*resolutionMetrics)
+	c.registrations = make(map[registrationKey]int64)
+	c.components = make(map[string]*ComponentMetrics)
+}
+
+// component returns the metrics of a component, creating them if needed.
+// The caller must hold the write lock.
+func (c *Collector) component(name string) *ComponentMetrics {
+	component, exists := c.components[name]
+	if !exists {
+		component = &ComponentMetrics{Name: name, Health: lifecycle.HealthStatusUnknown}
+		c.components[name] = component
+	}
+	return component
+}
+
+// typeName returns the metric label for a service type
+func typeName(serviceType reflect.Type) string {
+	if serviceType == nil {
+		return "<nil>"
+	}
+	return serviceType.String()
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AnasImloul/go-orchestrator/internal/di"
+	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
+)
+
+var (
+	_ di.MetricsProvider        = (*Collector)(nil)
+	_ lifecycle.MetricsRecorder = (*Collector)(nil)
+)
+
+func TestResolutionHistogramIsCumulative(t *testing.T) {
+	c := NewCollector(0.1, 0.01)
+	serviceType := reflect.TypeOf("")
+	for _, duration := range []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond, time.Second} {
+		c.RecordResolution(serviceType, int64(duration), true)
+	}
+	c.RecordResolution(serviceType, int64(time.Millisecond), false)
+
+	resolutions := c.Resolutions()
+	if len(resolutions) != 1 {
+		t.Fatalf("Resolutions() = %+v, want one service type", resolutions)
+	}
+	r := resolutions[0]
+	if r.Type != "string" || r.Successes != 4 || r.Failures != 1 {
+		t.Errorf("resolution = %+v, want string with 4 successes and 1 failure", r)
+	}
+	want := Histogram{
+		Buckets: []float64{0.01, 0.1},
+		Counts:  []uint64{3, 4}, // Bounds are inclusive
+		Count:   5,
+		Sum:     1.066,
+	}
+	if !reflect.DeepEqual(r.Latency.Buckets, want.Buckets) || !reflect.DeepEqual(r.Latency.Counts, want.Counts) || r.Latency.Count != want.Count {
+		t.Errorf("histogram = %+v, want %+v", r.Latency, want)
+	}
+	if diff := r.Latency.Sum - want.Sum; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("histogram sum = %v, want %v", r.Latency.Sum, want.Sum)
+	}
+}
+
+func TestComponentMetrics(t *testing.T) {
+	c := NewCollector()
+	c.RecordComponentStart("database", 20*time.Millisecond, 1, nil)
+	c.RecordComponentStart("database", 30*time.Millisecond, 3, errors.New("connection refused"))
+	c.RecordComponentStop("database", time.Millisecond, 1, nil)
+	c.RecordComponentRestart("database")
+	c.RecordComponentHealth("database", lifecycle.HealthStatusDegraded)
+	c.RecordComponentStart("api", time.Millisecond, 1, nil)
+
+	components := c.Components()
+	if len(components) != 2 || components[0].Name != "api" {
+		t.Fatalf("Components() = %+v, want api and database sorted by name", components)
+	}
+	want := ComponentMetrics{
+		Name:          "database",
+		Starts:        2,
+		StartFailures: 1,
+		Stops:         1,
+		Restarts:      1,
+		StartDuration: 30 * time.Millisecond,
+		StopDuration:  time.Millisecond,
+		StartAttempts: 3,
+		StopAttempts:  1,
+		Health:        lifecycle.HealthStatusDegraded,
+	}
+	if components[1] != want {
+		t.Errorf("database metrics = %+v, want %+v", components[1], want)
+	}
+	if components[0].Health != lifecycle.HealthStatusUnknown {
+		t.Errorf("api health = %s before any health check, want unknown", components[0].Health)
+	}
+
+	c.Reset()
+	if len(c.Components()) != 0 || len(c.Resolutions()) != 0 {
+		t.Error("metrics remain after Reset")
+	}
+}
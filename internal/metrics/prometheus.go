@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// healthStatuses are the states of the component health state set, in exposition order
+var healthStatuses = []lifecycle.HealthStatus{
+	lifecycle.HealthStatusHealthy,
+	lifecycle.HealthStatusDegraded,
+	lifecycle.HealthStatusUnhealthy,
+	lifecycle.HealthStatusUnknown,
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	resolutions := c.Resolutions()
+	components := c.Components()
+
+	c.mu.RLock()
+	registrations := make([]registrationKey, 0, len(c.registrations))
+	registrationCounts := make(map[registrationKey]int64, len(c.registrations))
+	for key, count := range c.registrations {
+		registrations = append(registrations, key)
+		registrationCounts[key] = count
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(registrations, func(i, j int) bool {
+		if registrations[i].serviceType != registrations[j].serviceType {
+			return registrations[i].serviceType < registrations[j].serviceType
+		}
+		return registrations[i].lifetime < registrations[j].lifetime
+	})
+
+	writeHeader(bw, "orchestrator_di_registrations_total", "counter", "Total number of service registrations.")
+	for _, key := range registrations {
+		fmt.Fprintf(bw, "orchestrator_di_registrations_total{type=%s,lifetime=%s} %d\n",
+			quote(key.serviceType), quote(key.lifetime), registrationCounts[key])
+	}
+
+	writeHeader(bw, "orchestrator_di_resolutions_total", "counter", "Total number of service resolutions.")
+	for _, r := range resolutions {
+		fmt.Fprintf(bw, "orchestrator_di_resolutions_total{type=%s,result=\"success\"} %d\n", quote(r.Type), r.Successes)
+		fmt.Fprintf(bw, "orchestrator_di_resolutions_total{type=%s,result=\"failure\"} %d\n", quote(r.Type), r.Failures)
+	}
+
+	writeHeader(bw, "orchestrator_di_resolution_duration_seconds", "histogram", "Latency of service resolutions.")
+	for _, r := range resolutions {
+		for i, bound := range r.Latency.Buckets {
+			fmt.Fprintf(bw, "orchestrator_di_resolution_duration_seconds_bucket{type=%s,le=%s} %d\n",
+				quote(r.Type), quote(formatFloat(bound)), r.Latency.Counts[i])
+		}
+		fmt.Fprintf(bw, "orchestrator_di_resolution_duration_seconds_bucket{type=%s,le=\"+Inf\"} %d\n", quote(r.Type), r.Latency.Count)
+		fmt.Fprintf(bw, "orchestrator_di_resolution_duration_seconds_sum{type=%s} %s\n", quote(r.Type), formatFloat(r.Latency.Sum))
+		fmt.Fprintf(bw, "orchestrator_di_resolution_duration_seconds_count{type=%s} %d\n", quote(r.Type), r.Latency.Count)
+	}
+
+	writeHeader(bw, "orchestrator_component_starts_total", "counter", "Total number of component starts.")
+	for _, m := range components {
+		fmt.Fprintf(bw, "orchestrator_component_starts_total{component=%s,result=\"success\"} %d\n", quote(m.Name), m.Starts-m.StartFailures)
+		fmt.Fprintf(bw, "orchestrator_component_starts_total{component=%s,result=\"failure\"} %d\n", quote(m.Name), m.StartFailures)
+	}
+
+	writeHeader(bw, "orchestrator_component_stops_total", "counter", "Total number of component stops.")
+	for _, m := range components {
+		fmt.Fprintf(bw, "orchestrator_component_stops_total{component=%s,result=\"success\"} %d\n", quote(m.Name), m.Stops-m.StopFailures)
+		fmt.Fprintf(bw, "orchestrator_component_stops_total{component=%s,result=\"failure\"} %d\n", quote(m.Name), m.StopFailures)
+	}
+
+	writeHeader(bw, "orchestrator_component_start_duration_seconds", "gauge", "Duration of the last component start, including retries.")
+	for _, m := range components {
+		fmt.Fprintf(bw, "orchestrator_component_start_duration_seconds{component=%s} %s\n", quote(m.Name), formatFloat(m.StartDuration.Seconds()))
+	}
+
+	writeHeader(bw, "orchestrator_component_stop_duration_seconds", "gauge", "Duration of the last component stop, including retries.")
+	for _, m := range components {
+		fmt.Fprintf(bw, "orchestrator_component_stop_duration_seconds{component=%s} %s\n", quote(m.Name), formatFloat(m.StopDuration.Seconds()))
+	}
+
+	writeHeader(bw, "orchestrator_component_restarts_total", "counter", "Total number of component restarts.")
+	for _, m := range components {
+		fmt.Fprintf(bw, "orchestrator_component_restarts_total{component=%s} %d\n", quote(m.Name), m.Restarts)
+	}
+
+	writeHeader(bw, "orchestrator_component_health", "gauge", "Current component health status; 1 for the current status and 0 otherwise.")
+	for _, m := range components {
+		for _, status := range healthStatuses {
+			value := 0
+			if m.Health == status {
+				value = 1
+			}
+			fmt.Fprintf(bw, "orchestrator_component_health{component=%s,status=%s} %d\n", quote(m.Name), quote(string(status)), value)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Handler returns an HTTP handler that serves all metrics in the Prometheus text exposition format.
+// It can be mounted on any HTTP mux, e.g. mux.Handle("/metrics", collector.Handler()).
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := c.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric family
+func writeHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// quote quotes a label value, escaping backslashes, double quotes and line feeds
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+// formatFloat formats a sample value
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnasImloul/go-orchestrator/internal/di"
+	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	c := NewCollector(0.01)
+	serviceType := reflect.TypeOf("")
+	c.RecordRegistration(serviceType, di.Singleton)
+	c.RecordResolution(serviceType, int64(5*time.Millisecond), true)
+	c.RecordResolution(serviceType, int64(20*time.Millisecond), false)
+	c.RecordComponentStart(`db "main"`, 1500*time.Millisecond, 2, nil)
+	c.RecordComponentStop(`db "main"`, 250*time.Millisecond, 1, nil)
+	c.RecordComponentRestart(`db "main"`)
+	c.RecordComponentHealth(`db "main"`, lifecycle.HealthStatusHealthy)
+
+	var b strings.Builder
+	if err := c.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() = %v", err)
+	}
+
+	want := `# HELP orchestrator_di_registrations_total Total number of service registrations.
+# TYPE orchestrator_di_registrations_total counter
+orchestrator_di_registrations_total{type="string",lifetime="singleton"} 1
+# HELP orchestrator_di_resolutions_total Total number of service resolutions.
+# TYPE orchestrator_di_resolutions_total counter
+orchestrator_di_resolutions_total{type="string",result="success"} 1
+orchestrator_di_resolutions_total{type="string",result="failure"} 1
+# HELP orchestrator_di_resolution_duration_seconds Latency of service resolutions.
+# TYPE orchestrator_di_resolution_duration_seconds histogram
+orchestrator_di_resolution_duration_seconds_bucket{type="string",le="0.01"} 1
+orchestrator_di_resolution_duration_seconds_bucket{type="string",le="+Inf"} 2
+orchestrator_di_resolution_duration_seconds_sum{type="string"} 0.025
+orchestrator_di_resolution_duration_seconds_count{type="string"} 2
+# HELP orchestrator_component_starts_total Total number of component starts.
+# TYPE orchestrator_component_starts_total counter
+orchestrator_component_starts_total{component="db \"main\"",result="success"} 1
+orchestrator_component_starts_total{component="db \"main\"",result="failure"} 0
+# HELP orchestrator_component_stops_total Total number of component stops.
+# TYPE orchestrator_component_stops_total counter
+orchestrator_component_stops_total{component="db \"main\"",result="success"} 1
+orchestrator_component_stops_total{component="db \"main\"",result="failure"} 0
+# HELP orchestrator_component_start_duration_seconds Duration of the last component start, including retries.
+# TYPE orchestrator_component_start_duration_seconds gauge
+orchestrator_component_start_duration_seconds{component="db \"main\""} 1.5
+# HELP orchestrator_component_stop_duration_seconds Duration of the last component stop, including retries.
+# TYPE orchestrator_component_stop_duration_seconds gauge
+orchestrator_component_stop_duration_seconds{component="db \"main\""} 0.25
+# HELP orchestrator_component_restarts_total Total number of component restarts.
+# TYPE orchestrator_component_restarts_total counter
+orchestrator_component_restarts_total{component="db \"main\""} 1
+# HELP orchestrator_component_health Current component health status; 1 for the current status and 0 otherwise.
+# TYPE orchestrator_component_health gauge
+orchestrator_component_health{component="db \"main\"",status="healthy"} 1
+orchestrator_component_health{component="db \"main\"",status="degraded"} 0
+orchestrator_component_health{component="db \"main\"",status="unhealthy"} 0
+orchestrator_component_health{component="db \"main\"",status="unknown"} 0
+`
+	if got := b.String(); got != want {
+		t.Errorf("WritePrometheus() wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQuoteEscapesLabelValues(t *testing.T) {
+	if got, want := quote("a\\b\"c\nd"), `"a\\b\"c\nd"`; got != want {
+		t.Errorf("quote() = %s, want %s", got, want)
+	}
+}
+
+func TestHandlerServesExpositionFormat(t *testing.T) {
+	c := NewCollector()
+	c.RecordComponentRestart("api")
+
+	recorder := httptest.NewRecorder()
+	c.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Handler() responded %d with content type %q", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(recorder.Body.String(), `orchestrator_component_restarts_total{component="api"} 1`) {
+		t.Errorf("Handler() served\n%s\nwithout the restart of api", recorder.Body)
+	}
+}
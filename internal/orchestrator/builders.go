@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
 )
@@ -15,12 +16,23 @@ type ServiceDefinitionInterface interface {
 
 // TypedServiceDefinition represents a type-safe service definition.
 type TypedServiceDefinition[T any] struct {
-	Name         string
-	Dependencies []string
-	Service      TypedServiceConfig[T]
-	Lifecycle    LifecycleConfig
-	RetryConfig  *lifecycle.RetryConfig
-	Metadata     map[string]string
+	Name                 string
+	Dependencies         []string
+	GroupDependencies    []reflect.Type
+	NamedDependencies    []string
+	OptionalDependencies []string
+	Service              TypedServiceConfig[T]
+	Lifecycle            LifecycleConfig
+	RetryConfig          *lifecycle.RetryConfig
+	RestartConfig        *lifecycle.RestartConfig
+	StartTimeout         time.Duration
+	StopTimeout          time.Duration
+	Metadata             map[string]string
+
+	factoryType      reflect.Type   // Type of the auto-wired factory function, if any
+	params           []param        // Factory parameters and injected fields, for validation
+	paramNames       map[int]string // Named bindings of the factory parameters, by index
+	unexportedFields bool           // Whether NewStructInjected may inject unexported fields
 }
 
 // WithLifecycle sets the lifecycle configuration for the typed service definition.
@@ -35,6 +47,35 @@ func (tsd *TypedServiceDefinition[T]) WithRetryConfig(config *lifecycle.RetryCon
 	return tsd
 }
 
+// WithRestartConfig sets the restart configuration for the typed service definition.
+func (tsd *TypedServiceDefinition[T]) WithRestartConfig(config *lifecycle.RestartConfig) *TypedServiceDefinition[T] {
+	tsd.RestartConfig = config
+	return tsd
+}
+
+// WithRestartPolicy sets the restart policy for the typed service definition,
+// using the default restart limits and backoff.
+func (tsd *TypedServiceDefinition[T]) WithRestartPolicy(policy RestartPolicy) *TypedServiceDefinition[T] {
+	config := lifecycle.DefaultRestartConfig(policy)
+	tsd.RestartConfig = &config
+	return tsd
+}
+
+// WithStartTimeout sets the maximum duration of a single Start call for the typed service definition.
+// It overrides Config.ServiceStartTimeout. If Start overruns the timeout it is abandoned and the context
+// passed to it is cancelled; once Start returns in time, background work may keep using the context.
+func (tsd *TypedServiceDefinition[T]) WithStartTimeout(timeout time.Duration) *TypedServiceDefinition[T] {
+	tsd.StartTimeout = timeout
+	return tsd
+}
+
+// WithStopTimeout sets the maximum duration of a single Stop call for the typed service definition.
+// It overrides Config.ServiceStopTimeout.
+func (tsd *TypedServiceDefinition[T]) WithStopTimeout(timeout time.Duration) *TypedServiceDefinition[T] {
+	tsd.StopTimeout = timeout
+	return tsd
+}
+
 // WithDependencies sets the dependencies for the typed service definition.
 func (tsd *TypedServiceDefinition[T]) WithDependencies(deps ...string) *TypedServiceDefinition[T] {
 	tsd.Dependencies = deps
@@ -58,12 +99,99 @@ func (tsd *TypedServiceDefinition[T]) WithName(name string) *TypedServiceDefinit
 	return tsd
 }
 
+// WithInterceptor adds an interceptor around the creation of the service's instances.
+// Interceptors and decorators added first are outermost.
+func (tsd *TypedServiceDefinition[T]) WithInterceptor(interceptor Interceptor) *TypedServiceDefinition[T] {
+	tsd.Service.Interceptors = append(tsd.Service.Interceptors, interceptor)
+	return tsd
+}
+
+// WithDecorator wraps every instance the container creates for the service, for example in a
+// logging, caching or metrics proxy. Decorators are interceptors, so they follow the same ordering:
+// the decorator added first is outermost and wraps the result of the ones added after it.
+func (tsd *TypedServiceDefinition[T]) WithDecorator(decorator func(T) T) *TypedServiceDefinition[T] {
+	return tsd.WithInterceptor(InterceptorFunc(func(ctx context.Context, serviceType reflect.Type, next func() (interface{}, error)) (interface{}, error) {
+		instance, err := next()
+		if err != nil {
+			return nil, err
+		}
+		typed, ok := instance.(T)
+		if !ok {
+			return nil, fmt.Errorf("cannot decorate service %s: instance of type %T does not implement %s", serviceType.String(), instance, serviceType.String())
+		}
+		return decorator(typed), nil
+	}))
+}
+
+// AsGroup makes the service a member of a group. Services of the same type in a group do not replace
+// each other: they are resolved together with ResolveAll or ResolveGroup, and injected into factories
+// that take a []T parameter. Like any definition, each member needs a unique name, so members of the
+// same type must be told apart with WithName.
+func (tsd *TypedServiceDefinition[T]) AsGroup(group string) *TypedServiceDefinition[T] {
+	tsd.Service.Group = group
+	return tsd
+}
+
+// WithPriority sets the position of the service within its group. Higher priorities are resolved
+// first; services with the same priority are resolved in registration order.
+func (tsd *TypedServiceDefinition[T]) WithPriority(priority int) *TypedServiceDefinition[T] {
+	tsd.Service.Priority = priority
+	return tsd
+}
+
+// AsNamed registers the service under a name, so that several services of the same type can be told apart
+// by the factory parameters bound to them with WithParamName or inject tags. It also names the definition,
+// which can be overridden with WithName.
+func (tsd *TypedServiceDefinition[T]) AsNamed(name string) *TypedServiceDefinition[T] {
+	tsd.Service.Name = name
+	tsd.Name = name
+	return tsd
+}
+
+// WithParamName binds the factory parameter at the given index to the service registered under a name,
+// instead of resolving it by type. The named service also replaces the parameter's lifecycle dependency.
+// It panics if the definition has no auto-wired factory or the index is out of range.
+func (tsd *TypedServiceDefinition[T]) WithParamName(index int, name string) *TypedServiceDefinition[T] {
+	if tsd.factoryType == nil {
+		panic(fmt.Sprintf("service %s has no auto-wired factory parameters", tsd.Name))
+	}
+	if index < 0 || index >= tsd.factoryType.NumIn() {
+		panic(fmt.Sprintf("service %s has no factory parameter %d", tsd.Name, index))
+	}
+	if name == "" {
+		panic(fmt.Sprintf("service %s: factory parameter %d must be bound to a non-empty name", tsd.Name, index))
+	}
+
+	paramType := tsd.factoryType.In(index)
+	removeDependency(tsd, paramType, binding{name: tsd.paramNames[index]})
+	tsd.paramNames[index] = name
+	addDependency(tsd, paramType, binding{name: name})
+	return tsd
+}
+
+// WithLifetime sets the lifetime of the service.
+func (tsd *TypedServiceDefinition[T]) WithLifetime(lifetime Lifetime) *TypedServiceDefinition[T] {
+	tsd.Service.Lifetime = lifetime
+	return tsd
+}
+
+// WithUnexportedFields allows NewStructInjected to inject unexported fields with an inject tag.
+// Without it, tagged unexported fields make the resolution fail.
+func (tsd *TypedServiceDefinition[T]) WithUnexportedFields() *TypedServiceDefinition[T] {
+	tsd.unexportedFields = true
+	return tsd
+}
+
 // ToServiceDefinition converts a typed service definition to a regular service definition.
 // This allows typed service definitions to work with the existing registration system.
 func (tsd *TypedServiceDefinition[T]) ToServiceDefinition() *ServiceDefinition {
 	return &ServiceDefinition{
-		Name:         tsd.Name,
-		Dependencies: tsd.Dependencies,
+		Name:                 tsd.Name,
+		Dependencies:         tsd.Dependencies,
+		GroupDependencies:    tsd.GroupDependencies,
+		NamedDependencies:    tsd.NamedDependencies,
+		OptionalDependencies: tsd.OptionalDependencies,
+		params:               tsd.params,
 		Services: []ServiceConfig{
 			{
 				Name: tsd.Service.Name,
@@ -71,12 +199,18 @@ func (tsd *TypedServiceDefinition[T]) ToServiceDefinition() *ServiceDefinition {
 				Factory: func(ctx context.Context, container *Container) (interface{}, error) {
 					return tsd.Service.Factory(ctx, container)
 				},
-				Lifetime: tsd.Service.Lifetime,
+				Lifetime:     tsd.Service.Lifetime,
+				Interceptors: tsd.Service.Interceptors,
+				Group:        tsd.Service.Group,
+				Priority:     tsd.Service.Priority,
 			},
 		},
-		Lifecycle:   tsd.Lifecycle,
-		RetryConfig: tsd.RetryConfig,
-		Metadata:    tsd.Metadata,
+		Lifecycle:     tsd.Lifecycle,
+		RetryConfig:   tsd.RetryConfig,
+		RestartConfig: tsd.RestartConfig,
+		StartTimeout:  tsd.StartTimeout,
+		StopTimeout:   tsd.StopTimeout,
+		Metadata:      tsd.Metadata,
 	}
 }
 
@@ -132,6 +266,24 @@ func (sd *ServiceDefinition) WithRetryConfig(config *lifecycle.RetryConfig) *Ser
 	return sd
 }
 
+// WithRestartConfig sets the restart configuration for the service definition.
+func (sd *ServiceDefinition) WithRestartConfig(config *lifecycle.RestartConfig) *ServiceDefinition {
+	sd.RestartConfig = config
+	return sd
+}
+
+// WithStartTimeout sets the maximum duration of a single Start call for the service definition.
+func (sd *ServiceDefinition) WithStartTimeout(timeout time.Duration) *ServiceDefinition {
+	sd.StartTimeout = timeout
+	return sd
+}
+
+// WithStopTimeout sets the maximum duration of a single Stop call for the service definition.
+func (sd *ServiceDefinition) WithStopTimeout(timeout time.Duration) *ServiceDefinition {
+	sd.StopTimeout = timeout
+	return sd
+}
+
 // WithAutoDependencies enables automatic dependency discovery for the last registered service.
 // This will scan the factory function parameters and automatically resolve dependencies.
 func (sd *ServiceDefinition) WithAutoDependencies() *ServiceDefinition {
@@ -154,6 +306,31 @@ func (sd *ServiceDefinition) WithAutoDependencies() *ServiceDefinition {
 	return sd
 }
 
+// WithInterceptor adds an interceptor around the creation of instances of every service in the definition.
+// Interceptors added first are outermost.
+func (sd *ServiceDefinition) WithInterceptor(interceptor Interceptor) *ServiceDefinition {
+	for i := range sd.Services {
+		sd.Services[i].Interceptors = append(sd.Services[i].Interceptors, interceptor)
+	}
+	return sd
+}
+
+// AsGroup makes every service in the definition a member of a group.
+func (sd *ServiceDefinition) AsGroup(group string) *ServiceDefinition {
+	for i := range sd.Services {
+		sd.Services[i].Group = group
+	}
+	return sd
+}
+
+// WithPriority sets the position within their group of every service in the definition.
+func (sd *ServiceDefinition) WithPriority(priority int) *ServiceDefinition {
+	for i := range sd.Services {
+		sd.Services[i].Priority = priority
+	}
+	return sd
+}
+
 // WithMetadata adds metadata to the service definition.
 func (sd *ServiceDefinition) WithMetadata(key, value string) *ServiceDefinition {
 	if sd.Metadata == nil {
@@ -239,43 +416,17 @@ func NewStructSingleton[T any](instance T) *TypedServiceDefinition[T] {
 // The factory function can return any struct type T.
 // Dependencies are automatically discovered from the factory function parameters.
 // No lifecycle management is provided - structs are registered as-is.
+// The factory function returns T, (T, error) or (T, func(), error). A returned cleanup function is called
+// when the owner of the instance is torn down, in reverse creation order.
 func NewStructFactory[T any](factory interface{}, lifetime Lifetime) *TypedServiceDefinition[T] {
 	// Get the struct type T
 	structType := reflect.TypeOf((*T)(nil)).Elem()
 	serviceName := inferServiceNameFromType(structType)
 
 	// Convert the factory function to the expected signature
-	factoryFunc := func(ctx context.Context, container *Container) (T, error) {
-		// Use reflection to call the original factory function with resolved dependencies
-		factoryValue := reflect.ValueOf(factory)
-		factoryType := factoryValue.Type()
-
-		// Get the number of parameters the factory function expects
-		numParams := factoryType.NumIn()
-		args := make([]reflect.Value, numParams)
-
-		// Resolve each dependency
-		for i := 0; i < numParams; i++ {
-			paramType := factoryType.In(i)
-			instance, err := container.Resolve(paramType)
-			if err != nil {
-				var zero T
-				return zero, fmt.Errorf("failed to resolve dependency %d (%s): %w", i, paramType.String(), err)
-			}
-			args[i] = reflect.ValueOf(instance)
-		}
-
-		// Call the factory function
-		results := factoryValue.Call(args)
-		if len(results) != 1 {
-			var zero T
-			return zero, fmt.Errorf("factory function should return exactly one value, got %d", len(results))
-		}
-
-		// Convert the result to type T
-		result := results[0].Interface().(T)
-		return result, nil
-	}
+	checkFactory(factory)
+	paramNames := make(map[int]string)
+	factoryFunc := autoWiredFactory[T](factory, paramNames)
 
 	// Create typed service definition without lifecycle management
 	serviceDef := &TypedServiceDefinition[T]{
@@ -290,52 +441,95 @@ func NewStructFactory[T any](factory interface{}, lifetime Lifetime) *TypedServi
 	}
 
 	// Automatically discover and add dependencies based on factory parameters
+	serviceDef.paramNames = paramNames
 	autoDiscoverDependenciesTyped(serviceDef, factory)
 
 	return serviceDef
 }
 
+// NewStructInjected creates a new service definition for a struct whose dependencies are injected into its fields,
+// instead of being passed to a factory function. T must be a struct or a pointer to a struct.
+// Fields tagged `inject:""` are resolved by type and fields tagged `inject:"name"` by name. With an ",optional"
+// suffix, such as `inject:",optional"`, a field is left zero when no matching service is registered.
+// Unexported fields are only injected after WithUnexportedFields.
+// Dependencies are automatically discovered from the tagged fields. The service is a singleton unless
+// changed with WithLifetime, and if T has a Start method it is called when the service starts.
+func NewStructInjected[T any]() *TypedServiceDefinition[T] {
+	// Get the struct type T
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	targetType := structType
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	if targetType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("NewStructInjected requires a struct or a pointer to a struct, got %s", structType))
+	}
+	serviceName := inferServiceNameFromType(structType)
+
+	var serviceDef *TypedServiceDefinition[T]
+
+	// Create the struct and inject its tagged fields
+	factoryFunc := func(ctx context.Context, container *Container) (T, error) {
+		instance, err := injectStruct(ctx, container, structType, serviceDef.unexportedFields)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return instance.Interface().(T), nil
+	}
+
+	serviceDef = &TypedServiceDefinition[T]{
+		Name: serviceName,
+		Service: TypedServiceConfig[T]{
+			Type:     structType,
+			Factory:  factoryFunc,
+			Lifetime: Singleton,
+		},
+	}
+
+	wireStart(serviceDef)
+
+	// Automatically discover and add dependencies based on the tagged fields
+	for _, field := range injectedFields(targetType) {
+		addDependency(serviceDef, field.Type, fieldBinding(field))
+	}
+
+	return serviceDef
+}
+
+// wireStart starts the service when the registry starts if its type has a Start method.
+// Services without a Start method are registered as-is.
+func wireStart[T any](serviceDef *TypedServiceDefinition[T]) {
+	serviceType := serviceDef.Service.Type
+	if !serviceType.Implements(reflect.TypeOf((*interface{ Start(context.Context) error })(nil)).Elem()) {
+		return
+	}
+	serviceDef.Lifecycle.Start = func(ctx context.Context, container *Container) error {
+		instance, err := container.ResolveContext(ctx, serviceType)
+		if err != nil {
+			return err
+		}
+		return instance.(interface{ Start(context.Context) error }).Start(ctx)
+	}
+}
+
 // NewAutoServiceFactory creates a new service definition with automatic dependency discovery and lifecycle management.
 // The factory function can return any type T - it doesn't need to implement the Service interface.
 // Dependencies are automatically discovered from the factory function parameters.
 // Lifecycle methods are automatically provided with sensible defaults.
+// The factory function returns T, (T, error) or (T, func(), error). A returned cleanup function is called
+// when the owner of the instance is torn down, in reverse creation order.
+// The lifetime must be Singleton, since Stop and Health act on the instance that was started;
+// Start and Add fail for other lifetimes.
 func NewAutoServiceFactory[T any](factory interface{}, lifetime Lifetime) *TypedServiceDefinition[T] {
 	// Get the interface type T
 	interfaceType := reflect.TypeOf((*T)(nil)).Elem()
 	serviceName := inferServiceNameFromType(interfaceType)
 
 	// Convert the factory function to the expected signature
-	factoryFunc := func(ctx context.Context, container *Container) (T, error) {
-		// Use reflection to call the original factory function with resolved dependencies
-		factoryValue := reflect.ValueOf(factory)
-		factoryType := factoryValue.Type()
-
-		// Get the number of parameters the factory function expects
-		numParams := factoryType.NumIn()
-		args := make([]reflect.Value, numParams)
-
-		// Resolve each dependency
-		for i := 0; i < numParams; i++ {
-			paramType := factoryType.In(i)
-			instance, err := container.Resolve(paramType)
-			if err != nil {
-				var zero T
-				return zero, fmt.Errorf("failed to resolve dependency %d (%s): %w", i, paramType.String(), err)
-			}
-			args[i] = reflect.ValueOf(instance)
-		}
-
-		// Call the factory function
-		results := factoryValue.Call(args)
-		if len(results) != 1 {
-			var zero T
-			return zero, fmt.Errorf("factory function should return exactly one value, got %d", len(results))
-		}
-
-		// Convert the result to type T
-		result := results[0].Interface().(T)
-		return result, nil
-	}
+	checkFactory(factory)
+	paramNames := make(map[int]string)
+	factoryFunc := autoWiredFactory[T](factory, paramNames)
 
 	// Create typed service definition with automatic lifecycle wiring
 	serviceDef := &TypedServiceDefinition[T]{
@@ -349,7 +543,7 @@ func NewAutoServiceFactory[T any](factory interface{}, lifetime Lifetime) *Typed
 		Lifecycle: LifecycleConfig{
 			Start: func(ctx context.Context, container *Container) error {
 				// Try to call Start method if it exists
-				instance, err := container.Resolve(interfaceType)
+				instance, err := container.ResolveContext(ctx, interfaceType)
 				if err != nil {
 					return err
 				}
@@ -359,17 +553,13 @@ func NewAutoServiceFactory[T any](factory interface{}, lifetime Lifetime) *Typed
 				// No Start method, default to no-op
 				return nil
 			},
-			Stop: func(ctx context.Context) error {
-				// Try to call Stop method if it exists
-				// We'll use the serviceComponent's container instead of creating a new one
-				// This will be handled by the serviceComponent.Stop method
-				return nil
-			},
-			// No Health function - let serviceComponent.Health handle automatic detection
+			// No Stop and Health functions - serviceComponent calls the Stop and Health methods
+			// of the started instance if it has them, and otherwise detects health automatically
 		},
 	}
 
 	// Automatically discover and add dependencies based on factory parameters
+	serviceDef.paramNames = paramNames
 	autoDiscoverDependenciesTyped(serviceDef, factory)
 
 	return serviceDef
@@ -379,25 +569,20 @@ func NewAutoServiceFactory[T any](factory interface{}, lifetime Lifetime) *Typed
 // The factory function must return a type T that implements the Service interface.
 // Dependencies are automatically discovered from the factory function parameters.
 // Lifecycle methods (Start, Stop, Health) are automatically wired.
+// The factory function returns T, (T, error) or (T, func(), error). A returned cleanup function is called
+// when the owner of the instance is torn down, in reverse creation order.
+// The lifetime must be Singleton, since Stop and Health act on the instance that was started;
+// Start and Add fail for other lifetimes.
 func NewServiceFactory[T Service](factory interface{}, lifetime Lifetime) *TypedServiceDefinition[T] {
 	// Get the interface type T
 	interfaceType := reflect.TypeOf((*T)(nil)).Elem()
 	serviceName := inferServiceNameFromType(interfaceType)
 
 	// Validate that the factory function returns the correct type
-	factoryValue := reflect.ValueOf(factory)
-	factoryType := factoryValue.Type()
-
-	if factoryType.Kind() != reflect.Func {
-		panic("factory must be a function")
-	}
-
-	if factoryType.NumOut() == 0 {
-		panic("factory function must return a value")
-	}
+	checkFactory(factory)
 
 	// Check that the return type matches T
-	returnType := factoryType.Out(0)
+	returnType := reflect.TypeOf(factory).Out(0)
 	expectedType := interfaceType
 
 	// For interface types, we need to check if the return type implements the interface
@@ -412,9 +597,8 @@ func NewServiceFactory[T Service](factory interface{}, lifetime Lifetime) *Typed
 	}
 
 	// Create a wrapper factory that handles automatic dependency injection
-	wrapperFactory := func(ctx context.Context, container *Container) (T, error) {
-		return callFactoryWithAutoDependencies[T](ctx, container, factory)
-	}
+	paramNames := make(map[int]string)
+	wrapperFactory := autoWiredFactory[T](factory, paramNames)
 
 	// Create typed service definition with automatic lifecycle wiring
 	serviceDef := &TypedServiceDefinition[T]{
@@ -427,30 +611,18 @@ func NewServiceFactory[T Service](factory interface{}, lifetime Lifetime) *Typed
 		// Automatically wire lifecycle methods
 		Lifecycle: LifecycleConfig{
 			Start: func(ctx context.Context, container *Container) error {
-				service, err := ResolveType[T](container)
+				instance, err := container.ResolveContext(ctx, interfaceType)
 				if err != nil {
 					return err
 				}
-				return service.Start(ctx)
-			},
-			Stop: func(ctx context.Context) error {
-				// For factory-created services, we need to resolve the service and call Stop
-				// We'll need to get the container from the service registry
-				// This is a limitation we'll address by storing the registry reference
-				return nil // We'll implement this properly by modifying the serviceComponent
-			},
-			Health: func(ctx context.Context) HealthStatus {
-				// For factory services, we need to resolve from the registry's container
-				// This is a limitation we'll address by modifying the serviceComponent
-				return HealthStatus{
-					Status:  HealthStatusHealthy,
-					Message: "Service is healthy",
-				}
+				return instance.(T).Start(ctx)
 			},
+			// No Stop and Health functions - serviceComponent calls them on the started instance
 		},
 	}
 
 	// Automatically discover and add dependencies based on factory parameters
+	serviceDef.paramNames = paramNames
 	autoDiscoverDependenciesTyped(serviceDef, factory)
 
 	return serviceDef
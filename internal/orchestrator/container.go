@@ -10,27 +10,16 @@ import (
 
 // Register registers a service with the container.
 func (c *Container) Register(serviceType reflect.Type, factory func(ctx context.Context, container *Container) (interface{}, error), lifetime Lifetime) error {
-	// Convert public Lifetime to internal ServiceLifetime
-	var internalLifetime di.ServiceLifetime
-	switch lifetime {
-	case Transient:
-		internalLifetime = di.Transient
-	case Scoped:
-		internalLifetime = di.Scoped
-	case Singleton:
-		internalLifetime = di.Singleton
-	default:
-		internalLifetime = di.Singleton
-	}
-
-	// Register with the internal container using the proper lifetime
-	return c.container.Register(serviceType, func(ctx context.Context, cont di.Container) (interface{}, error) {
-		return factory(ctx, c)
-	}, di.WithLifetime(internalLifetime))
+	return c.register(serviceType, factory, lifetime)
 }
 
 // RegisterNamed registers a named service with the container.
 func (c *Container) RegisterNamed(name string, serviceType reflect.Type, factory func(ctx context.Context, container *Container) (interface{}, error), lifetime Lifetime) error {
+	return c.register(serviceType, factory, lifetime, di.WithName(name))
+}
+
+// register registers a service with the internal container using the given options.
+func (c *Container) register(serviceType reflect.Type, factory func(ctx context.Context, container *Container) (interface{}, error), lifetime Lifetime, options ...di.Option) error {
 	// Convert public Lifetime to internal ServiceLifetime
 	var internalLifetime di.ServiceLifetime
 	switch lifetime {
@@ -44,19 +33,29 @@ func (c *Container) RegisterNamed(name string, serviceType reflect.Type, factory
 		internalLifetime = di.Singleton
 	}
 
-	// Register with the internal container using the proper lifetime and name
+	// Register with the internal container using the proper lifetime
+	options = append([]di.Option{di.WithLifetime(internalLifetime)}, options...)
 	return c.container.Register(serviceType, func(ctx context.Context, cont di.Container) (interface{}, error) {
 		return factory(ctx, c)
-	}, di.WithLifetime(internalLifetime), di.WithName(name))
+	}, options...)
 }
 
 // RegisterInstance registers a service instance.
+// On a scope, the instance is only resolvable within the scope and its children, such as the current
+// request or tenant, and factories of scoped and transient services resolved in the scope can inject it.
 func (c *Container) RegisterInstance(serviceType reflect.Type, instance interface{}) error {
+	if c.scope != nil {
+		return c.scope.RegisterInstance(serviceType, instance)
+	}
 	return c.container.RegisterInstance(serviceType, instance)
 }
 
 // RegisterNamedInstance registers a named service instance.
+// On a scope, the instance is only resolvable within the scope and its children.
 func (c *Container) RegisterNamedInstance(name string, serviceType reflect.Type, instance interface{}) error {
+	if c.scope != nil {
+		return c.scope.RegisterNamedInstance(name, serviceType, instance)
+	}
 	return c.container.Register(serviceType, func(ctx context.Context, cont di.Container) (interface{}, error) {
 		return instance, nil
 	}, di.WithName(name), di.WithLifetime(di.Singleton))
@@ -64,18 +63,77 @@ func (c *Container) RegisterNamedInstance(name string, serviceType reflect.Type,
 
 // Resolve resolves a service from the container.
 func (c *Container) Resolve(serviceType reflect.Type) (interface{}, error) {
+	return c.ResolveContext(context.Background(), serviceType)
+}
+
+// ResolveContext resolves a service as part of the resolution or request in ctx. Scoped services come from
+// the scope of the container, or else from the scope in ctx, such as the request scope opened by
+// ServiceRegistry.ScopeMiddleware. Factories receive a context carrying that scope, so the dependencies
+// they resolve through ResolveContext come from the same scope and are nested below them in traces.
+func (c *Container) ResolveContext(ctx context.Context, serviceType reflect.Type) (interface{}, error) {
 	if c.scope != nil {
-		return c.scope.Resolve(serviceType)
+		ctx = di.WithScope(ctx, c.scope)
+	}
+	// The lifecycle of a named service or group member resolves itself by name, since the registration
+	// by type may belong to another service of the same type
+	if c.owner != nil {
+		for _, service := range c.owner.Services {
+			if name := containerName(c.owner, service); name != "" && service.Type == serviceType {
+				return c.container.ResolveByNameContext(ctx, name)
+			}
+		}
 	}
-	return c.container.Resolve(serviceType)
+	return c.container.ResolveContext(ctx, serviceType)
 }
 
-// ResolveByName resolves a service by name from the container.
-func (c *Container) ResolveByName(name string) (interface{}, error) {
+// has reports whether a service that a binding of the given type and name resolves to in ctx is registered.
+// Slices of services always resolve, to an empty slice if there are none.
+func (c *Container) has(ctx context.Context, serviceType reflect.Type, name string) bool {
+	if name != "" {
+		if scope := c.scopeOf(ctx); scope != nil {
+			return scope.ContainsByName(name)
+		}
+		return c.container.ContainsByName(name)
+	}
+	return isServiceSlice(serviceType) || c.contains(ctx, serviceType)
+}
+
+// contains reports whether a service of the given type is registered in the container or the scope of ctx.
+func (c *Container) contains(ctx context.Context, serviceType reflect.Type) bool {
+	if scope := c.scopeOf(ctx); scope != nil {
+		return scope.Contains(serviceType)
+	}
+	return c.container.Contains(serviceType)
+}
+
+// scopeOf returns the scope that scoped services resolved in ctx come from: the scope of the
+// container, or else the scope in ctx. It returns nil outside of any scope.
+func (c *Container) scopeOf(ctx context.Context) di.Scope {
 	if c.scope != nil {
-		return c.scope.ResolveByName(name)
+		return c.scope
 	}
-	return c.container.ResolveByName(name)
+	return di.GetScopeFromContext(ctx)
+}
+
+// resolveNamed resolves a named service as part of the resolution in ctx.
+func (c *Container) resolveNamed(ctx context.Context, name string) (interface{}, error) {
+	if scope := c.scopeOf(ctx); scope != nil {
+		return scope.ResolveByNameContext(ctx, name)
+	}
+	return c.container.ResolveByNameContext(ctx, name)
+}
+
+// resolveAll resolves every service of a type, restricted to a group if one is given.
+func (c *Container) resolveAll(ctx context.Context, serviceType reflect.Type, group string) ([]interface{}, error) {
+	if scope := c.scopeOf(ctx); scope != nil {
+		return scope.ResolveAllContext(ctx, serviceType, group)
+	}
+	return c.container.ResolveAll(ctx, serviceType, group)
+}
+
+// ResolveByName resolves a service by name from the container.
+func (c *Container) ResolveByName(name string) (interface{}, error) {
+	return c.resolveNamed(context.Background(), name)
 }
 
 // ResolveType resolves a service by interface type.
@@ -96,6 +154,61 @@ func ResolveType[T any](c *Container) (T, error) {
 	return instance.(T), nil
 }
 
+// ResolveTypeCtx resolves a service by interface type from the container in ctx, as placed there by
+// ServiceRegistry.ScopeMiddleware or ContextWithContainer. Scoped services come from the scope of the request.
+// T must be an interface type, not a concrete struct.
+func ResolveTypeCtx[T any](ctx context.Context) (T, error) {
+	var zero T
+	serviceType := reflect.TypeOf((*T)(nil)).Elem()
+
+	// Enforce that T is an interface type
+	if serviceType.Kind() != reflect.Interface {
+		return zero, fmt.Errorf("ResolveTypeCtx[T] requires T to be an interface type, got %s", serviceType.Kind())
+	}
+
+	return resolveFromContext[T](ctx, serviceType)
+}
+
+// ResolveStructCtx resolves a service by struct type from the container in ctx.
+// T can be any type (interface or struct).
+func ResolveStructCtx[T any](ctx context.Context) (T, error) {
+	return resolveFromContext[T](ctx, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// resolveFromContext resolves a service of type T from the container in ctx.
+func resolveFromContext[T any](ctx context.Context, serviceType reflect.Type) (T, error) {
+	var zero T
+	container := ContainerFromContext(ctx)
+	if container == nil {
+		return zero, fmt.Errorf("no container in context to resolve %s from", serviceType.String())
+	}
+
+	instance, err := container.ResolveContext(ctx, serviceType)
+	if err != nil {
+		return zero, err
+	}
+	return instance.(T), nil
+}
+
+// containerKey is the context key of the container.
+type containerKey struct{}
+
+// ContextWithContainer returns a context carrying the container, to be resolved from with ResolveTypeCtx.
+// If the container is a scope, scoped services resolved through ctx come from that scope.
+func ContextWithContainer(ctx context.Context, container *Container) context.Context {
+	ctx = context.WithValue(ctx, containerKey{}, container)
+	if container.scope != nil {
+		ctx = di.WithScope(ctx, container.scope)
+	}
+	return ctx
+}
+
+// ContainerFromContext returns the container in ctx, or nil if there is none.
+func ContainerFromContext(ctx context.Context) *Container {
+	container, _ := ctx.Value(containerKey{}).(*Container)
+	return container
+}
+
 // ResolveStruct resolves a service by struct type.
 // T can be any type (interface or struct).
 func ResolveStruct[T any](c *Container) (T, error) {
@@ -109,6 +222,38 @@ func ResolveStruct[T any](c *Container) (T, error) {
 	return instance.(T), nil
 }
 
+// ResolveAll resolves every service of type T, including the members of every group.
+// Services are ordered by priority, highest first, and then by registration order.
+func ResolveAll[T any](c *Container) ([]T, error) {
+	return resolveAll[T](c, "")
+}
+
+// ResolveGroup resolves the services of type T in a group, ordered by priority, highest first,
+// and then by registration order.
+func ResolveGroup[T any](c *Container, group string) ([]T, error) {
+	return resolveAll[T](c, group)
+}
+
+// resolveAll resolves every service of type T, restricted to a group if one is given.
+func resolveAll[T any](c *Container, group string) ([]T, error) {
+	serviceType := reflect.TypeOf((*T)(nil)).Elem()
+
+	instances, err := c.resolveAll(context.Background(), serviceType, group)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, 0, len(instances))
+	for _, instance := range instances {
+		typed, ok := instance.(T)
+		if !ok {
+			return nil, fmt.Errorf("service of type %T does not implement %s", instance, serviceType.String())
+		}
+		result = append(result, typed)
+	}
+	return result, nil
+}
+
 // CreateScope creates a new scope for the container.
 func (c *Container) CreateScope() *Container {
 	scope := c.container.CreateScope()
@@ -118,6 +263,20 @@ func (c *Container) CreateScope() *Container {
 	}
 }
 
+// CreateChild creates a child scope of the scope, which inherits its scoped and registered instances.
+// Instances registered on the child override those of the parent, and scoped services the parent has not
+// created yet are created by the child. Disposing the parent disposes its children first.
+// On a container without a scope, CreateChild creates a new scope like CreateScope.
+func (c *Container) CreateChild() *Container {
+	if c.scope == nil {
+		return c.CreateScope()
+	}
+	return &Container{
+		container: c.container,
+		scope:     c.scope.CreateChild(),
+	}
+}
+
 // Dispose disposes the scope if one exists.
 func (c *Container) Dispose() error {
 	if c.scope != nil {
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unsafe"
 
+	"github.com/AnasImloul/go-orchestrator/internal/di"
 	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
 )
 
@@ -14,21 +16,301 @@ import (
 func autoDiscoverDependenciesTyped[T any](serviceDef *TypedServiceDefinition[T], factory interface{}) {
 	factoryValue := reflect.ValueOf(factory)
 	factoryType := factoryValue.Type()
+	serviceDef.factoryType = factoryType
 
 	// Get parameter types from the factory function
 	for i := 0; i < factoryType.NumIn(); i++ {
 		paramType := factoryType.In(i)
-		
+
+		// The fields of a parameter struct are dependencies of their own
+		if isParamStruct(paramType) {
+			for _, field := range paramStructFields(paramType) {
+				addDependency(serviceDef, field.Type, fieldBinding(field))
+			}
+			continue
+		}
+
+		addDependency(serviceDef, paramType, binding{name: serviceDef.paramNames[i]})
+	}
+}
+
+// binding describes how a factory parameter or injected field is resolved.
+type binding struct {
+	name     string // Name of the service to resolve; resolved by type if empty
+	optional bool   // Whether to leave the zero value when no matching service is registered
+}
+
+// fieldBinding returns the binding declared by the inject tag of a struct field:
+// `inject:""` resolves by type, `inject:"name"` by name, and an ",optional" suffix makes the field optional.
+func fieldBinding(field reflect.StructField) binding {
+	name, options, _ := strings.Cut(field.Tag.Get("inject"), ",")
+	b := binding{name: name}
+	for _, option := range strings.Split(options, ",") {
+		if option == "optional" {
+			b.optional = true
+		}
+	}
+	return b
+}
+
+// optionalParam is implemented by the Optional[T] parameter wrapper of the public package.
+// The wrapper types live there because the public package cannot alias generic types.
+type optionalParam interface {
+	DependencyType() reflect.Type
+	Present() bool
+	WithValue(instance interface{}) interface{}
+}
+
+// lazyParam is implemented by the Lazy[T] parameter wrapper of the public package.
+type lazyParam interface {
+	DependencyType() reflect.Type
+	WithResolver(resolve func() (interface{}, error)) interface{}
+}
+
+var (
+	optionalParamType = reflect.TypeOf((*optionalParam)(nil)).Elem()
+	lazyParamType     = reflect.TypeOf((*lazyParam)(nil)).Elem()
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	cleanupType = reflect.TypeOf((*func())(nil)).Elem()
+)
+
+// isProvider determines if a type is a provider, func() (T, error) or func(context.Context) (T, error),
+// which is injected with a function that resolves a service of type T on every call.
+func isProvider(paramType reflect.Type) bool {
+	if paramType.Kind() != reflect.Func || paramType.IsVariadic() || paramType.NumOut() != 2 || paramType.Out(1) != errorType {
+		return false
+	}
+	switch paramType.NumIn() {
+	case 0:
+		return true
+	case 1:
+		return paramType.In(0) == contextType
+	default:
+		return false
+	}
+}
+
+// makeProvider creates a provider that resolves a service of its result type on every call, so that a transient
+// service is created anew and a scoped service comes from the scope of the resolution that injected the provider.
+func makeProvider(ctx context.Context, container *Container, providerType reflect.Type, b binding) reflect.Value {
+	serviceType := providerType.Out(0)
+	scope := di.GetScopeFromContext(ctx)
+	// The provider may be called long after the factory returned, so keep the context values but not its cancellation
+	ctx = context.WithoutCancel(ctx)
+
+	return reflect.MakeFunc(providerType, func(args []reflect.Value) []reflect.Value {
+		callCtx := ctx
+		if len(args) == 1 {
+			if argCtx, ok := args[0].Interface().(context.Context); ok && argCtx != nil {
+				callCtx = argCtx
+				if scope != nil && di.GetScopeFromContext(argCtx) == nil {
+					callCtx = di.WithScope(argCtx, scope)
+				}
+			}
+		}
+
+		value, err := resolveDependency(callCtx, container, serviceType, binding{name: b.name})
+		if err != nil {
+			return []reflect.Value{reflect.Zero(serviceType), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value, reflect.Zero(errorType)}
+	})
+}
+
+// asOptionalParam returns the zero value of an Optional[T] parameter type.
+func asOptionalParam(paramType reflect.Type) (optionalParam, bool) {
+	if paramType.Kind() == reflect.Interface || !paramType.Implements(optionalParamType) {
+		return nil, false
+	}
+	return reflect.Zero(paramType).Interface().(optionalParam), true
+}
+
+// asLazyParam returns the zero value of a Lazy[T] parameter type.
+func asLazyParam(paramType reflect.Type) (lazyParam, bool) {
+	if paramType.Kind() == reflect.Interface || !paramType.Implements(lazyParamType) {
+		return nil, false
+	}
+	return reflect.Zero(paramType).Interface().(lazyParam), true
+}
+
+// param is a factory parameter or injected field of a service, as recorded for validation.
+type param struct {
+	paramType reflect.Type
+	binding   binding
+}
+
+// addDependency records a factory parameter or injected field of a typed service definition,
+// together with the lifecycle dependency it implies.
+func addDependency[T any](serviceDef *TypedServiceDefinition[T], paramType reflect.Type, b binding) {
+	serviceDef.params = append(serviceDef.params, param{paramType: paramType, binding: b})
+	addLifecycleDependency(serviceDef, paramType, b)
+}
+
+// addLifecycleDependency records a factory parameter or injected field as a lifecycle dependency of a typed service definition.
+func addLifecycleDependency[T any](serviceDef *TypedServiceDefinition[T], paramType reflect.Type, b binding) {
+	if optional, ok := asOptionalParam(paramType); ok {
+		b.optional = true
+		addLifecycleDependency(serviceDef, optional.DependencyType(), b)
+		return
+	}
+	if _, ok := asLazyParam(paramType); ok || isProvider(paramType) {
+		// Lazy dependencies and providers are resolved after construction, so they do not constrain the startup order
+		return
+	}
+
+	switch {
+	case isServiceSlice(paramType) && b.name == "":
+		// A slice of services depends on every service in it, which is only known once all services are registered
+		serviceDef.GroupDependencies = append(serviceDef.GroupDependencies, paramType.Elem())
+	case b.optional:
+		// An optional binding only depends on a service that is actually registered
+		if dependencyName, ok := bindingDependencyName(paramType, b); ok {
+			serviceDef.OptionalDependencies = append(serviceDef.OptionalDependencies, dependencyName)
+		}
+	case b.name != "":
+		// A named binding depends on the service registered under that name
+		serviceDef.NamedDependencies = append(serviceDef.NamedDependencies, b.name)
+	case isLikelyServiceOrRegisteredStruct(paramType):
 		// Add as lifecycle dependency if it's likely to be a service or a registered struct
 		// This includes:
 		// 1. Interface types (likely services)
 		// 2. Structs implementing the Service interface
 		// 3. Struct types that are registered as services (for dependency ordering)
-		if isLikelyServiceOrRegisteredStruct(paramType) {
-			dependencyName := typeToDependencyName(paramType)
-			serviceDef.Dependencies = append(serviceDef.Dependencies, dependencyName)
+		dependencyName := typeToDependencyName(paramType)
+		serviceDef.Dependencies = append(serviceDef.Dependencies, dependencyName)
+	}
+}
+
+// removeDependency removes a parameter and its dependency recorded by addDependency.
+func removeDependency[T any](serviceDef *TypedServiceDefinition[T], paramType reflect.Type, b binding) {
+	serviceDef.params = removeFirst(serviceDef.params, param{paramType: paramType, binding: b})
+	removeLifecycleDependency(serviceDef, paramType, b)
+}
+
+// removeLifecycleDependency removes a lifecycle dependency recorded by addLifecycleDependency.
+func removeLifecycleDependency[T any](serviceDef *TypedServiceDefinition[T], paramType reflect.Type, b binding) {
+	if optional, ok := asOptionalParam(paramType); ok {
+		b.optional = true
+		removeLifecycleDependency(serviceDef, optional.DependencyType(), b)
+		return
+	}
+	if _, ok := asLazyParam(paramType); ok || isProvider(paramType) {
+		return
+	}
+
+	switch {
+	case isServiceSlice(paramType) && b.name == "":
+		serviceDef.GroupDependencies = removeFirst(serviceDef.GroupDependencies, paramType.Elem())
+	case b.optional:
+		if dependencyName, ok := bindingDependencyName(paramType, b); ok {
+			serviceDef.OptionalDependencies = removeFirst(serviceDef.OptionalDependencies, dependencyName)
+		}
+	case b.name != "":
+		serviceDef.NamedDependencies = removeFirst(serviceDef.NamedDependencies, b.name)
+	case isLikelyServiceOrRegisteredStruct(paramType):
+		serviceDef.Dependencies = removeFirst(serviceDef.Dependencies, typeToDependencyName(paramType))
+	}
+}
+
+// bindingDependencyName returns the name a binding is recorded under as a dependency:
+// the service name for named bindings, and the inferred service name for likely services.
+func bindingDependencyName(paramType reflect.Type, b binding) (string, bool) {
+	if b.name != "" {
+		return b.name, true
+	}
+	if isLikelyServiceOrRegisteredStruct(paramType) {
+		return typeToDependencyName(paramType), true
+	}
+	return "", false
+}
+
+// removeFirst removes the first occurrence of a value from a slice.
+func removeFirst[E comparable](values []E, value E) []E {
+	for i, v := range values {
+		if v == value {
+			return append(values[:i:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+// isParamStruct determines if a factory parameter is a parameter struct, a struct whose fields are
+// injected individually. A struct is a parameter struct if any of its fields has an inject tag.
+func isParamStruct(paramType reflect.Type) bool {
+	if paramType.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < paramType.NumField(); i++ {
+		if hasInjectTag(paramType.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// paramStructFields returns the injected fields of a parameter struct, which are its exported fields.
+func paramStructFields(paramType reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < paramType.NumField(); i++ {
+		if field := paramType.Field(i); field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// injectedFields returns the fields of a struct that have an inject tag.
+func injectedFields(structType reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < structType.NumField(); i++ {
+		if field := structType.Field(i); hasInjectTag(field) {
+			fields = append(fields, field)
 		}
 	}
+	return fields
+}
+
+// hasInjectTag determines if a struct field has an inject tag.
+func hasInjectTag(field reflect.StructField) bool {
+	_, ok := field.Tag.Lookup("inject")
+	return ok
+}
+
+// injectStruct creates a value of a struct type, or of a pointer to a struct type, and injects its tagged fields.
+// Unexported fields are only injected if allowed, and are otherwise reported as an error.
+func injectStruct(ctx context.Context, container *Container, structType reflect.Type, unexported bool) (reflect.Value, error) {
+	targetType := structType
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+
+	target := reflect.New(targetType)
+	for _, field := range injectedFields(targetType) {
+		if !field.IsExported() && !unexported {
+			return reflect.Value{}, fmt.Errorf("cannot inject unexported field %s of %s without WithUnexportedFields", field.Name, targetType.String())
+		}
+
+		value, err := resolveDependency(ctx, container, field.Type, fieldBinding(field))
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to inject field %s of %s: %w", field.Name, targetType.String(), err)
+		}
+
+		fieldValue := target.Elem().FieldByIndex(field.Index)
+		if !field.IsExported() {
+			// Unexported fields cannot be set through reflection, so write them through their address
+			fieldValue = reflect.NewAt(field.Type, unsafe.Pointer(fieldValue.UnsafeAddr())).Elem()
+		}
+		fieldValue.Set(value)
+	}
+
+	if structType.Kind() == reflect.Ptr {
+		return target, nil
+	}
+	return target.Elem(), nil
 }
 
 // isLikelyServiceOrRegisteredStruct determines if a type is likely to be a service or registered struct
@@ -38,22 +320,27 @@ func isLikelyServiceOrRegisteredStruct(paramType reflect.Type) bool {
 	if paramType.Kind() == reflect.Ptr {
 		paramType = paramType.Elem()
 	}
-	
+
 	// If it's an interface, it's likely a service
 	if paramType.Kind() == reflect.Interface {
 		return true
 	}
-	
+
 	// If it's a struct, include it as a potential dependency
 	// Let the DAG validation handle any issues with missing dependencies
 	if paramType.Kind() == reflect.Struct {
 		return true
 	}
-	
+
 	// For other types (like logger.Logger), don't treat as lifecycle dependency
 	return false
 }
 
+// isServiceSlice determines if a type is a slice of services, which is injected with every service of its element type.
+func isServiceSlice(paramType reflect.Type) bool {
+	return paramType.Kind() == reflect.Slice && isLikelyServiceOrRegisteredStruct(paramType.Elem())
+}
+
 // isLikelyService determines if a type is likely to be a service that needs lifecycle management
 // This is kept for backward compatibility but now delegates to isLikelyServiceOrRegisteredStruct
 func isLikelyService(paramType reflect.Type) bool {
@@ -141,6 +428,27 @@ func mapHealthStatus(status HealthStatusType) lifecycle.HealthStatus {
 	}
 }
 
+// toHealthStatus converts a lifecycle ComponentHealth to a HealthStatus
+func toHealthStatus(health lifecycle.ComponentHealth) HealthStatus {
+	var statusType HealthStatusType
+	switch health.Status {
+	case lifecycle.HealthStatusHealthy:
+		statusType = HealthStatusHealthy
+	case lifecycle.HealthStatusDegraded:
+		statusType = HealthStatusDegraded
+	case lifecycle.HealthStatusUnhealthy:
+		statusType = HealthStatusUnhealthy
+	default:
+		statusType = HealthStatusUnknown
+	}
+
+	return HealthStatus{
+		Status:  statusType,
+		Message: health.Message,
+		Details: health.Details,
+	}
+}
+
 // typeToDependencyName converts a Go type to a dependency name.
 // Uses the same robust naming strategy as service registration to ensure consistency.
 // IMPORTANT: This must match exactly how service names are generated to ensure
@@ -151,19 +459,140 @@ func typeToDependencyName(paramType reflect.Type) string {
 	return inferServiceNameFromType(paramType)
 }
 
+// resolveDependency resolves a factory parameter or injected field according to its binding.
+// A slice of services that is not registered itself is injected with every service of its element
+// type, in priority and registration order, and a parameter struct is injected field by field.
+// Optional[T] and Lazy[T] wrappers are injected with their dependency if present, or a resolver for it,
+// and providers of type func() (T, error) or func(context.Context) (T, error) with a function resolving T.
+func resolveDependency(ctx context.Context, container *Container, paramType reflect.Type, b binding) (reflect.Value, error) {
+	if optional, ok := asOptionalParam(paramType); ok {
+		dependencyType := optional.DependencyType()
+		if !container.has(ctx, dependencyType, b.name) {
+			return reflect.Zero(paramType), nil
+		}
+		value, err := resolveDependency(ctx, container, dependencyType, binding{name: b.name})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(optional.WithValue(value.Interface())), nil
+	}
+
+	if isProvider(paramType) && !container.contains(ctx, paramType) {
+		return makeProvider(ctx, container, paramType, b), nil
+	}
+
+	if lazy, ok := asLazyParam(paramType); ok {
+		dependencyType := lazy.DependencyType()
+		// Get may be called long after the factory returned, so keep the context values but not its cancellation
+		ctx = context.WithoutCancel(ctx)
+		return reflect.ValueOf(lazy.WithResolver(func() (interface{}, error) {
+			value, err := resolveDependency(ctx, container, dependencyType, b)
+			if err != nil {
+				return nil, err
+			}
+			return value.Interface(), nil
+		})), nil
+	}
+
+	if b.optional && !container.has(ctx, paramType, b.name) {
+		return reflect.Zero(paramType), nil
+	}
+
+	if name := b.name; name != "" {
+		instance, err := container.resolveNamed(ctx, name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if instance != nil && !reflect.TypeOf(instance).AssignableTo(paramType) {
+			return reflect.Value{}, fmt.Errorf("service '%s' of type %T is not assignable to %s", name, instance, paramType.String())
+		}
+		return instanceValue(instance, paramType), nil
+	}
+
+	if isParamStruct(paramType) {
+		params := reflect.New(paramType).Elem()
+		for _, field := range paramStructFields(paramType) {
+			value, err := resolveDependency(ctx, container, field.Type, fieldBinding(field))
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("failed to resolve field %s: %w", field.Name, err)
+			}
+			params.FieldByIndex(field.Index).Set(value)
+		}
+		return params, nil
+	}
+
+	if isServiceSlice(paramType) && !container.contains(ctx, paramType) {
+		instances, err := container.resolveAll(ctx, paramType.Elem(), "")
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice := reflect.MakeSlice(paramType, 0, len(instances))
+		for _, instance := range instances {
+			slice = reflect.Append(slice, instanceValue(instance, paramType.Elem()))
+		}
+		return slice, nil
+	}
+
+	instance, err := container.ResolveContext(ctx, paramType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return instanceValue(instance, paramType), nil
+}
+
+// instanceValue returns the reflect.Value of a resolved instance, using the zero value of the type for nil instances.
+func instanceValue(instance interface{}, instanceType reflect.Type) reflect.Value {
+	if instance == nil {
+		return reflect.Zero(instanceType)
+	}
+	return reflect.ValueOf(instance)
+}
+
+// checkFactory panics unless factory is a function returning T, (T, error) or (T, func(), error).
+func checkFactory(factory interface{}) {
+	if factory == nil {
+		panic("factory must be a function")
+	}
+	factoryType := reflect.TypeOf(factory)
+	if factoryType.Kind() != reflect.Func {
+		panic("factory must be a function")
+	}
+
+	switch factoryType.NumOut() {
+	case 1:
+		return
+	case 2:
+		if factoryType.Out(1) == errorType {
+			return
+		}
+	case 3:
+		if factoryType.Out(1) == cleanupType && factoryType.Out(2) == errorType {
+			return
+		}
+	}
+	panic(fmt.Sprintf("factory function must return T, (T, error) or (T, func(), error), got %s", factoryType))
+}
+
+// autoWiredFactory returns the factory of a service whose factory function has its dependencies resolved
+// automatically. The cleanup function returned by the factory function, if any, is registered with the owner
+// of the instance.
+func autoWiredFactory[T any](factory interface{}, paramNames map[int]string) func(ctx context.Context, container *Container) (T, error) {
+	return func(ctx context.Context, container *Container) (T, error) {
+		instance, cleanup, err := callFactoryWithAutoDependencies[T](ctx, container, factory, paramNames)
+		di.AddCleanup(ctx, cleanup)
+		return instance, err
+	}
+}
+
 // callFactoryWithAutoDependencies uses reflection to automatically resolve dependencies
 // and call the factory function with the resolved dependencies.
-func callFactoryWithAutoDependencies[T any](ctx context.Context, container *Container, factory interface{}) (T, error) {
+// The factory function returns T, (T, error) or (T, func(), error), as checked by checkFactory.
+func callFactoryWithAutoDependencies[T any](ctx context.Context, container *Container, factory interface{}, paramNames map[int]string) (T, func(), error) {
 	var zero T
 
 	factoryValue := reflect.ValueOf(factory)
 	factoryType := factoryValue.Type()
 
-	// Check if it's a function
-	if factoryType.Kind() != reflect.Func {
-		return zero, fmt.Errorf("factory must be a function, got %s", factoryType.Kind())
-	}
-
 	// Get the number of parameters
 	numIn := factoryType.NumIn()
 	args := make([]reflect.Value, numIn)
@@ -173,31 +602,37 @@ func callFactoryWithAutoDependencies[T any](ctx context.Context, container *Cont
 		paramType := factoryType.In(i)
 
 		// Try to resolve the dependency from the container
-		dependency, err := container.Resolve(paramType)
+		dependency, err := resolveDependency(ctx, container, paramType, binding{name: paramNames[i]})
 		if err != nil {
-			return zero, fmt.Errorf("failed to resolve dependency %d of type %s: %w", i, paramType.String(), err)
+			return zero, nil, fmt.Errorf("failed to resolve dependency %d of type %s: %w", i, paramType.String(), err)
 		}
 
-		args[i] = reflect.ValueOf(dependency)
+		args[i] = dependency
 	}
 
 	// Call the factory function
 	results := factoryValue.Call(args)
 
 	// Handle the return values
-	if len(results) == 0 {
-		return zero, fmt.Errorf("factory function must return at least one value")
+	var cleanup func()
+	if len(results) == 3 {
+		cleanup, _ = results[1].Interface().(func())
 	}
-
-	// Check if the first result is an error
 	if len(results) > 1 {
-		if err, ok := results[1].Interface().(error); ok && err != nil {
-			return zero, err
+		if err, ok := results[len(results)-1].Interface().(error); ok && err != nil {
+			return zero, cleanup, err
 		}
 	}
 
-	// Return the first result
-	return results[0].Interface().(T), nil
+	// Nil interface values come back as nil, which leaves the zero value
+	if results[0].Kind() == reflect.Interface && results[0].IsNil() {
+		return zero, cleanup, nil
+	}
+	instance, ok := results[0].Interface().(T)
+	if !ok {
+		return zero, cleanup, fmt.Errorf("factory returned %s, which is not %s", results[0].Type(), reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return instance, cleanup, nil
 }
 
 // resolveDependenciesAndCallFactory uses reflection to automatically resolve dependencies
@@ -0,0 +1,381 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// replicatedClient connects to a primary and a replica database of the same type
+type replicatedClient struct {
+	primary *provideConfig
+	replica *provideConfig
+}
+
+// replicaParams are the parameters of a replicated client, bound by their inject tags
+type replicaParams struct {
+	Primary *provideConfig `inject:"primary"`
+	Replica *provideConfig `inject:"replica"`
+}
+
+// registerDatabases registers a primary and a replica config under their names
+func registerDatabases(registry *ServiceRegistry) {
+	registry.Register(NewStructFactory[*provideConfig](func() *provideConfig { return &provideConfig{dsn: "primary"} }, Singleton).
+		AsNamed("primary"))
+	registry.Register(NewStructFactory[*provideConfig](func() *provideConfig { return &provideConfig{dsn: "replica"} }, Singleton).
+		AsNamed("replica"))
+}
+
+// dependenciesInGraph returns the dependencies of a service in the registry's graph
+func dependenciesInGraph(registry *ServiceRegistry, name string) []string {
+	for _, node := range registry.Graph().Nodes {
+		if node.Name == name {
+			return node.Dependencies
+		}
+	}
+	return nil
+}
+
+func TestNamedParametersResolveByName(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*replicatedClient](func(replica, primary *provideConfig) *replicatedClient {
+		return &replicatedClient{primary: primary, replica: replica}
+	}, Singleton).WithName("client").WithParamName(0, "replica").WithParamName(1, "primary"))
+	registerDatabases(registry)
+	startRegistry(t, registry)
+
+	client, err := ResolveStruct[*replicatedClient](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if client.primary.dsn != "primary" || client.replica.dsn != "replica" {
+		t.Errorf("client got %s and %s, want primary and replica", client.primary.dsn, client.replica.dsn)
+	}
+	if deps := dependenciesInGraph(registry, "client"); !reflect.DeepEqual(deps, []string{"replica", "primary"}) {
+		t.Errorf("client depends on %v, want the named services instead of their type", deps)
+	}
+}
+
+func TestParamStructBindsFieldsByInjectTag(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*replicatedClient](func(params replicaParams) *replicatedClient {
+		return &replicatedClient{primary: params.Primary, replica: params.Replica}
+	}, Singleton).WithName("client"))
+	registerDatabases(registry)
+	startRegistry(t, registry)
+
+	client, err := ResolveStruct[*replicatedClient](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if client.primary.dsn != "primary" || client.replica.dsn != "replica" {
+		t.Errorf("client got %s and %s, want primary and replica", client.primary.dsn, client.replica.dsn)
+	}
+	if deps := dependenciesInGraph(registry, "client"); !reflect.DeepEqual(deps, []string{"primary", "replica"}) {
+		t.Errorf("client depends on %v, want the services named by the tags", deps)
+	}
+}
+
+func TestNamedParameterOfWrongType(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton).AsNamed("users"))
+	registry.Register(NewStructFactory[*replicatedClient](func(primary *provideConfig) *replicatedClient {
+		return &replicatedClient{primary: primary}
+	}, Singleton).WithName("client").WithParamName(0, "users"))
+	startRegistry(t, registry)
+
+	_, err := ResolveStruct[*replicatedClient](registry.Container())
+	if err == nil || !strings.Contains(err.Error(), "not assignable") {
+		t.Errorf("ResolveStruct() = %v, want an error for the handler bound to a config parameter", err)
+	}
+}
+
+func TestWithParamNamePanicsOnInvalidIndex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("WithParamName accepted a parameter the factory doesn't have")
+		}
+	}()
+	NewStructFactory[*replicatedClient](func(primary *provideConfig) *replicatedClient {
+		return &replicatedClient{primary: primary}
+	}, Singleton).WithParamName(1, "replica")
+}
+
+// injectedRepository has its dependencies injected into its tagged fields
+type injectedRepository struct {
+	Primary  *provideConfig `inject:"primary"`
+	Handler  groupHandler   `inject:""`
+	Missing  *provideClient `inject:",optional"`
+	Untagged *provideConfig
+}
+
+// startedRepository is an injected struct with a Start method
+type startedRepository struct {
+	Database *namedDependency `inject:""`
+	started  bool
+}
+
+func (r *startedRepository) Start(ctx context.Context) error {
+	r.started = r.Database != nil
+	return nil
+}
+
+// unexportedRepository has an injected unexported field
+type unexportedRepository struct {
+	handler groupHandler `inject:""`
+}
+
+func TestNewStructInjectedInjectsTaggedFields(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructInjected[*injectedRepository]().WithName("repository"))
+	registerDatabases(registry)
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton))
+	startRegistry(t, registry)
+
+	repository, err := ResolveStruct[*injectedRepository](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if repository.Primary.dsn != "primary" || repository.Handler.Route() != "/users" {
+		t.Errorf("repository got %+v, want the primary config and users handler", repository)
+	}
+	if repository.Missing != nil || repository.Untagged != nil {
+		t.Errorf("repository got %+v, want the optional and untagged fields left nil", repository)
+	}
+	handlerName := typeToDependencyName(reflect.TypeOf((*groupHandler)(nil)).Elem())
+	if deps := dependenciesInGraph(registry, "repository"); !reflect.DeepEqual(deps, []string{handlerName, "primary"}) {
+		t.Errorf("repository depends on %v, want the services of its required fields", deps)
+	}
+}
+
+func TestNewStructInjectedStartsStruct(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructInjected[*startedRepository]())
+	registry.Register(NewStructFactory[*namedDependency](func() *namedDependency { return &namedDependency{} }, Singleton))
+	startRegistry(t, registry)
+
+	repository, err := ResolveStruct[*startedRepository](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if !repository.started {
+		t.Error("Start of the injected struct was not called after injecting its fields")
+	}
+}
+
+func TestNewStructInjectedUnexportedFields(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		registry := newTestRegistry()
+		serviceDef := NewStructInjected[*unexportedRepository]()
+		if allow {
+			serviceDef.WithUnexportedFields()
+		}
+		registry.Register(serviceDef)
+		registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton))
+		startRegistry(t, registry)
+
+		repository, err := ResolveStruct[*unexportedRepository](registry.Container())
+		switch {
+		case !allow && (err == nil || !strings.Contains(err.Error(), "WithUnexportedFields")):
+			t.Errorf("ResolveStruct() = %v without WithUnexportedFields, want an error", err)
+		case allow && err != nil:
+			t.Errorf("ResolveStruct() = %v with WithUnexportedFields", err)
+		case allow && repository.handler == nil:
+			t.Error("unexported field was not injected")
+		}
+	}
+}
+
+func TestNewStructInjectedPanicsForNonStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewStructInjected accepted a non-struct type")
+		}
+	}()
+	NewStructInjected[groupHandler]()
+}
+
+// connectionPool creates connections with injected providers
+type connectionPool struct {
+	connect        func() (*provideClient, error)
+	connectContext func(context.Context) (*provideClient, error)
+}
+
+func TestProviderParametersResolveOnEveryCall(t *testing.T) {
+	registry := newTestRegistry()
+	created := 0
+	registry.Register(NewStructFactory[*provideClient](func() *provideClient {
+		created++
+		return &provideClient{}
+	}, Transient))
+	registry.Register(NewStructFactory[*connectionPool](func(connect func() (*provideClient, error), connectContext func(context.Context) (*provideClient, error)) *connectionPool {
+		return &connectionPool{connect: connect, connectContext: connectContext}
+	}, Singleton).WithName("pool"))
+	startRegistry(t, registry)
+
+	pool, err := ResolveStruct[*connectionPool](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if created != 0 {
+		t.Errorf("%d clients created before calling the provider", created)
+	}
+	first, err := pool.connect()
+	if err != nil {
+		t.Fatalf("provider failed: %v", err)
+	}
+	second, err := pool.connectContext(context.Background())
+	if err != nil {
+		t.Fatalf("provider failed: %v", err)
+	}
+	if first == second || created != 2 {
+		t.Errorf("providers created %d clients, want a new transient client per call", created)
+	}
+	if deps := dependenciesInGraph(registry, "pool"); len(deps) != 0 {
+		t.Errorf("pool depends on %v, want providers not to constrain the startup order", deps)
+	}
+}
+
+func TestProviderReportsResolutionError(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*connectionPool](func(connect func() (*provideClient, error)) *connectionPool {
+		return &connectionPool{connect: connect}
+	}, Singleton))
+	startRegistry(t, registry)
+
+	pool, err := ResolveStruct[*connectionPool](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if client, err := pool.connect(); err == nil || client != nil {
+		t.Errorf("provider returned %v, %v for an unregistered service, want an error", client, err)
+	}
+}
+
+func TestIsProvider(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		want  bool
+	}{
+		{func() (*provideClient, error) { return nil, nil }, true},
+		{func(context.Context) (*provideClient, error) { return nil, nil }, true},
+		{func() *provideClient { return nil }, false},
+		{func(string) (*provideClient, error) { return nil, nil }, false},
+		{func(context.Context, string) (*provideClient, error) { return nil, nil }, false},
+		{func(...context.Context) (*provideClient, error) { return nil, nil }, false},
+	} {
+		if got := isProvider(reflect.TypeOf(test.value)); got != test.want {
+			t.Errorf("isProvider(%T) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestCheckFactorySignatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory interface{}
+		valid   bool
+	}{
+		{"instance", func() *provideConfig { return nil }, true},
+		{"instance and error", func() (*provideConfig, error) { return nil, nil }, true},
+		{"instance, cleanup and error", func() (*provideConfig, func(), error) { return nil, nil, nil }, true},
+		{"not a function", &provideConfig{}, false},
+		{"no results", func() {}, false},
+		{"second result not an error", func() (*provideConfig, string) { return nil, "" }, false},
+		{"cleanup without error", func() (*provideConfig, func()) { return nil, nil }, false},
+		{"cleanup taking arguments", func() (*provideConfig, func(int), error) { return nil, nil, nil }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if panicked := recover() != nil; panicked == tt.valid {
+					t.Errorf("checkFactory() panicked = %v, want %v", panicked, !tt.valid)
+				}
+			}()
+			checkFactory(tt.factory)
+		})
+	}
+}
+
+func TestFactoryErrorFailsResolution(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*provideConfig](func() (*provideConfig, error) {
+		return nil, errors.New("config file not found")
+	}, Transient))
+	startRegistry(t, registry)
+
+	if _, err := ResolveStruct[*provideConfig](registry.Container()); err == nil || !strings.Contains(err.Error(), "config file not found") {
+		t.Errorf("ResolveStruct() = %v, want the factory error", err)
+	}
+}
+
+func TestFactoryCleanupsRunInReverseCreationOrder(t *testing.T) {
+	log := &lifecycleLog{}
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*provideConfig](func() (*provideConfig, func(), error) {
+		return &provideConfig{}, func() { log.record("clean up config") }, nil
+	}, Singleton))
+	registry.Register(NewStructFactory[*provideClient](func(config *provideConfig) (*provideClient, func(), error) {
+		return &provideClient{config: config}, func() { log.record("clean up client") }, nil
+	}, Singleton))
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if _, err := ResolveStruct[*provideClient](registry.Container()); err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if got := log.String(); got != "" {
+		t.Fatalf("cleanups ran %q before Stop", got)
+	}
+
+	if err := registry.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if got := log.String(); got != "clean up client, clean up config" {
+		t.Errorf("cleanups ran %q, want the client cleaned up before its config", got)
+	}
+}
+
+func TestFactoryCleanupsRunWithScope(t *testing.T) {
+	log := &lifecycleLog{}
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*provideConfig](func() (*provideConfig, func(), error) {
+		return &provideConfig{}, func() { log.record("clean up config") }, nil
+	}, Scoped))
+	registry.Register(NewStructFactory[*provideClient](func(config *provideConfig) (*provideClient, func(), error) {
+		return &provideClient{config: config}, func() { log.record("clean up client") }, nil
+	}, Transient))
+	startRegistry(t, registry)
+
+	scope := registry.Container().CreateScope()
+	for i := 0; i < 2; i++ {
+		if _, err := ResolveStruct[*provideClient](scope); err != nil {
+			t.Fatalf("ResolveStruct() = %v", err)
+		}
+	}
+	if err := scope.Dispose(); err != nil {
+		t.Fatalf("Dispose() = %v", err)
+	}
+
+	if got := log.String(); got != "clean up client, clean up client, clean up config" {
+		t.Errorf("cleanups ran %q, want both transients cleaned up before the scoped config", got)
+	}
+}
+
+func TestFactoryCleanupRunsWhenFactoryFails(t *testing.T) {
+	log := &lifecycleLog{}
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*provideConfig](func() (*provideConfig, func(), error) {
+		return nil, func() { log.record("clean up config") }, errors.New("config file not found")
+	}, Singleton))
+	startRegistry(t, registry)
+
+	if _, err := ResolveStruct[*provideConfig](registry.Container()); err == nil {
+		t.Fatal("ResolveStruct() succeeded, want the factory error")
+	}
+	if got := log.String(); got != "clean up config" {
+		t.Errorf("cleanups ran %q, want the cleanup of the failed factory run immediately", got)
+	}
+}
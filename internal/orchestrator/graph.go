@@ -0,0 +1,249 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Graph is a serializable snapshot of the service dependency graph.
+type Graph struct {
+	Phase  string      `json:"phase"`
+	Nodes  []GraphNode `json:"nodes"`
+	Levels [][]string  `json:"levels"`
+}
+
+// GraphNode describes a single service in the dependency graph.
+type GraphNode struct {
+	Name         string            `json:"name"`
+	Types        []string          `json:"types,omitempty"`
+	Lifetime     string            `json:"lifetime,omitempty"`
+	Dependencies []string          `json:"dependencies"`
+	Level        int               `json:"level"`   // -1 if the level cannot be determined (cycle)
+	Managed      bool              `json:"managed"` // Whether the service has a managed lifecycle
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Phase        string            `json:"phase,omitempty"`
+	Health       string            `json:"health,omitempty"`
+}
+
+// Graph returns a snapshot of the service dependency graph, including startup levels
+// and, once the registry has been started, the current phase and health of each service.
+func (sr *ServiceRegistry) Graph() *Graph {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	states := sr.lifecycleManager.GetAllComponentStates()
+
+	nodes := make(map[string]*GraphNode, len(sr.services)+1)
+	for name, serviceDef := range sr.services {
+		node := &GraphNode{
+			Name:         name,
+			Dependencies: append([]string{}, sr.dependenciesOf(serviceDef)...),
+			Managed: serviceDef.Lifecycle.Start != nil ||
+				serviceDef.Lifecycle.Stop != nil ||
+				serviceDef.Lifecycle.Health != nil,
+		}
+		for _, service := range serviceDef.Services {
+			if service.Type != nil {
+				node.Types = append(node.Types, service.Type.String())
+			}
+		}
+		if len(serviceDef.Services) > 0 {
+			node.Lifetime = serviceDef.Services[0].Lifetime.String()
+		}
+		if len(serviceDef.Metadata) > 0 {
+			node.Metadata = make(map[string]string, len(serviceDef.Metadata))
+			for key, value := range serviceDef.Metadata {
+				node.Metadata[key] = value
+			}
+		}
+		nodes[name] = node
+	}
+
+	// Include lifecycle components that have no service definition, such as the logger
+	for name, state := range states {
+		if _, exists := nodes[name]; !exists {
+			nodes[name] = &GraphNode{
+				Name:         name,
+				Lifetime:     Singleton.String(),
+				Dependencies: append([]string{}, state.Dependencies...),
+			}
+		}
+	}
+
+	for name, state := range states {
+		node := nodes[name]
+		node.Managed = true
+		node.Phase = string(state.Phase)
+		node.Health = toHealthStatus(state.Health).Status.String()
+	}
+
+	graph := &Graph{
+		Phase: string(sr.lifecycleManager.GetPhase()),
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	levels := make(map[string]int, len(nodes))
+	for _, name := range names {
+		graphLevel(name, nodes, levels, make(map[string]bool))
+	}
+
+	for _, name := range names {
+		node := nodes[name]
+		node.Level = levels[name]
+		if node.Level >= 0 {
+			for len(graph.Levels) <= node.Level {
+				graph.Levels = append(graph.Levels, []string{})
+			}
+			graph.Levels[node.Level] = append(graph.Levels[node.Level], name)
+		}
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+
+	return graph
+}
+
+// graphLevel computes the startup level of a node: 0 for nodes without dependencies and one more
+// than the highest level of its dependencies otherwise. Missing dependencies are ignored and
+// nodes on a cycle get level -1.
+func graphLevel(name string, nodes map[string]*GraphNode, levels map[string]int, visiting map[string]bool) int {
+	if level, done := levels[name]; done {
+		return level
+	}
+	if visiting[name] {
+		return -1
+	}
+	visiting[name] = true
+
+	level := 0
+	for _, dep := range nodes[name].Dependencies {
+		if _, exists := nodes[dep]; !exists {
+			continue
+		}
+		depLevel := graphLevel(dep, nodes, levels, visiting)
+		if depLevel < 0 {
+			level = -1
+			break
+		}
+		if depLevel+1 > level {
+			level = depLevel + 1
+		}
+	}
+
+	visiting[name] = false
+	levels[name] = level
+	return level
+}
+
+// JSON returns the graph encoded as indented JSON.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT returns the graph in Graphviz DOT format. Edges point from a service to its dependencies
+// and services on the same startup level are ranked together.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph services {\n")
+	b.WriteString("  rankdir=BT;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	for _, node := range g.Nodes {
+		attrs := fmt.Sprintf("label=%q", graphLabel(node, "\n"))
+		if color := healthColor(node.Health); color != "" {
+			attrs += fmt.Sprintf(", color=%q", color)
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", node.Name, attrs)
+	}
+
+	for _, level := range g.Levels {
+		if len(level) < 2 {
+			continue
+		}
+		quoted := make([]string, len(level))
+		for i, name := range level {
+			quoted[i] = fmt.Sprintf("%q", name)
+		}
+		fmt.Fprintf(&b, "  { rank=same; %s; }\n", strings.Join(quoted, "; "))
+	}
+
+	for _, node := range g.Nodes {
+		for _, dep := range node.Dependencies {
+			fmt.Fprintf(&b, "  %q -> %q;\n", node.Name, dep)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart. Edges point from a service to its dependencies.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+	}
+
+	b.WriteString("flowchart BT\n")
+
+	for _, node := range g.Nodes {
+		label := strings.ReplaceAll(graphLabel(node, "<br/>"), `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[node.Name], label)
+	}
+
+	for _, node := range g.Nodes {
+		for _, dep := range node.Dependencies {
+			depID, exists := ids[dep]
+			if !exists {
+				// Declare missing dependencies so the diagram still renders
+				depID = fmt.Sprintf("n%d", len(ids))
+				ids[dep] = depID
+				fmt.Fprintf(&b, "  %s[\"%s (missing)\"]\n", depID, strings.ReplaceAll(dep, `"`, "#quot;"))
+			}
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[node.Name], depID)
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if color := healthColor(node.Health); color != "" {
+			fmt.Fprintf(&b, "  style %s stroke:%s\n", ids[node.Name], color)
+		}
+	}
+
+	return b.String()
+}
+
+// graphLabel builds a node label from its name, lifetime and phase or health.
+func graphLabel(node GraphNode, separator string) string {
+	parts := []string{node.Name}
+	if node.Lifetime != "" {
+		parts = append(parts, node.Lifetime)
+	}
+	if node.Health != "" && node.Phase != "" {
+		parts = append(parts, node.Phase+", "+node.Health)
+	}
+	return strings.Join(parts, separator)
+}
+
+// healthColor returns the color used to draw a node with the given health status.
+func healthColor(health string) string {
+	switch health {
+	case "healthy":
+		return "green"
+	case "degraded":
+		return "orange"
+	case "unhealthy":
+		return "red"
+	default:
+		return ""
+	}
+}
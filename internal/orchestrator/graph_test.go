@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testGraph is a graph of an api depending on a healthy database and a missing cache
+var testGraph = &Graph{
+	Phase: "running",
+	Nodes: []GraphNode{
+		{Name: "api", Lifetime: "singleton", Dependencies: []string{"database", "cache"}, Level: 1, Managed: true, Phase: "running", Health: "degraded"},
+		{Name: "database", Lifetime: "singleton", Dependencies: []string{}, Level: 0, Managed: true, Phase: "running", Health: "healthy"},
+	},
+	Levels: [][]string{{"database"}, {"api"}},
+}
+
+func TestGraphOfRegistry(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*namedDependency](func() *namedDependency { return &namedDependency{} }, Singleton).
+		WithName("database").WithMetadata("team", "storage"))
+	registry.Register(NewStructFactory[*namedService](func() *namedService { return &namedService{} }, Singleton).
+		WithName("tracker").WithDependencies("database"))
+	registry.Register(NewStructFactory[*provideConfig](func() *provideConfig { return &provideConfig{} }, Transient).
+		WithName("config"))
+
+	graph := registry.Graph()
+
+	if graph.Phase != "stopped" {
+		t.Errorf("Phase = %q, want stopped before Start", graph.Phase)
+	}
+	want := [][]string{{"config", "database", "logger::Logger"}, {"tracker"}}
+	if !reflect.DeepEqual(graph.Levels, want) {
+		t.Errorf("Levels = %v, want %v", graph.Levels, want)
+	}
+	nodes := make(map[string]GraphNode)
+	for _, node := range graph.Nodes {
+		nodes[node.Name] = node
+	}
+	if tracker := nodes["tracker"]; tracker.Level != 1 || !reflect.DeepEqual(tracker.Dependencies, []string{"database"}) {
+		t.Errorf("tracker node = %+v, want level 1 depending on database", tracker)
+	}
+	if config := nodes["config"]; config.Lifetime != "transient" || config.Managed {
+		t.Errorf("config node = %+v, want an unmanaged transient", config)
+	}
+	if database := nodes["database"]; database.Metadata["team"] != "storage" {
+		t.Errorf("database node = %+v, want its metadata", database)
+	}
+
+	startRegistry(t, registry)
+	registry.Health(context.Background())
+	if database := registry.Graph().Nodes[1]; database.Phase != "running" || database.Health != "healthy" {
+		t.Errorf("database node after Start = %+v, want running and healthy", database)
+	}
+}
+
+func TestGraphLevelOfCycle(t *testing.T) {
+	nodes := map[string]*GraphNode{
+		"a":     {Name: "a", Dependencies: []string{"b"}},
+		"b":     {Name: "b", Dependencies: []string{"a"}},
+		"c":     {Name: "c", Dependencies: []string{"missing"}},
+		"inner": {Name: "inner", Dependencies: []string{"c"}},
+	}
+	levels := make(map[string]int)
+	for _, name := range []string{"a", "b", "c", "inner"} {
+		graphLevel(name, nodes, levels, make(map[string]bool))
+	}
+
+	want := map[string]int{"a": -1, "b": -1, "c": 0, "inner": 1}
+	if !reflect.DeepEqual(levels, want) {
+		t.Errorf("levels = %v, want %v", levels, want)
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	want := `digraph services {
+  rankdir=BT;
+  node [shape=box, style=rounded];
+  "api" [label="api\nsingleton\nrunning, degraded", color="orange"];
+  "database" [label="database\nsingleton\nrunning, healthy", color="green"];
+  "api" -> "database";
+  "api" -> "cache";
+}
+`
+	if got := testGraph.DOT(); got != want {
+		t.Errorf("DOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	want := `flowchart BT
+  n0["api<br/>singleton<br/>running, degraded"]
+  n1["database<br/>singleton<br/>running, healthy"]
+  n0 --> n1
+  n2["cache (missing)"]
+  n0 --> n2
+  style n0 stroke:orange
+  style n1 stroke:green
+`
+	if got := testGraph.Mermaid(); got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGraphJSON(t *testing.T) {
+	data, err := testGraph.JSON()
+	if err != nil {
+		t.Fatalf("JSON() = %v", err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("JSON() is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, testGraph) {
+		t.Errorf("JSON() decoded to %+v, want %+v", decoded, *testGraph)
+	}
+}
@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// prefixedHandler decorates a handler by prefixing its route
+type prefixedHandler struct {
+	groupHandler
+	prefix string
+}
+
+func (h *prefixedHandler) Route() string { return h.prefix + h.groupHandler.Route() }
+
+func TestInterceptorsAndDecoratorsRunOutermostFirst(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) Interceptor {
+		return InterceptorFunc(func(ctx context.Context, serviceType reflect.Type, next func() (interface{}, error)) (interface{}, error) {
+			calls = append(calls, "enter "+name)
+			instance, err := next()
+			calls = append(calls, "leave "+name)
+			return instance, err
+		})
+	}
+	registry := newTestRegistry()
+	registry.UseInterceptor(interceptor("registry"))
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Transient).
+		WithInterceptor(interceptor("first")).
+		WithDecorator(func(h groupHandler) groupHandler { return &prefixedHandler{h, "/v1"} }).
+		WithDecorator(func(h groupHandler) groupHandler { return &prefixedHandler{h, "/api"} }).
+		WithInterceptor(interceptor("second")))
+	startRegistry(t, registry)
+
+	calls = nil
+	handler, err := ResolveType[groupHandler](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveType() = %v", err)
+	}
+
+	// The decorator added first wraps the result of the one added after it
+	if route := handler.Route(); route != "/v1/api/users" {
+		t.Errorf("decorated route = %q, want /v1/api/users", route)
+	}
+	want := []string{"enter registry", "enter first", "enter second", "leave second", "leave first", "leave registry"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("interceptors ran as %v, want %v", calls, want)
+	}
+}
+
+func TestDecoratorAppliesToEveryInstance(t *testing.T) {
+	decorated := 0
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Transient).
+		WithDecorator(func(h groupHandler) groupHandler {
+			decorated++
+			return h
+		}))
+	startRegistry(t, registry)
+
+	for i := 0; i < 3; i++ {
+		if _, err := ResolveType[groupHandler](registry.Container()); err != nil {
+			t.Fatalf("ResolveType() = %v", err)
+		}
+	}
+	if decorated != 3 {
+		t.Errorf("decorator ran %d times for 3 transient instances", decorated)
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsCollectorIsOptIn(t *testing.T) {
+	registry := newTestRegistry()
+	if provider := registry.Metrics(); provider != nil {
+		t.Errorf("Metrics() = %T with the default config, want nil", provider)
+	}
+	recorder := httptest.NewRecorder()
+	registry.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("MetricsHandler() responded %d without a provider, want 404", recorder.Code)
+	}
+}
+
+func TestConfiguredMetricsCollector(t *testing.T) {
+	collector := NewMetricsCollector()
+	config := DefaultConfig()
+	config.HealthCheckInterval = 0
+	config.MetricsProvider = collector
+	registry := NewWithConfig(config)
+	registry.Register(NewStructFactory[*namedDependency](func() *namedDependency { return &namedDependency{} }, Singleton).
+		WithName("database"))
+	startRegistry(t, registry)
+
+	if registry.Metrics() != collector {
+		t.Fatal("Metrics() is not the configured collector")
+	}
+	if len(collector.Resolutions()) == 0 {
+		t.Error("collector recorded no resolutions")
+	}
+	var starts uint64
+	for _, component := range collector.Components() {
+		if component.Name == "database" {
+			starts = component.Starts
+		}
+	}
+	if starts != 1 {
+		t.Errorf("collector recorded %d starts of database, want 1", starts)
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `component="database"`) {
+		t.Errorf("MetricsHandler() responded %d with\n%s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestMetricsDisabledIgnoresProvider(t *testing.T) {
+	config := DefaultConfig()
+	config.HealthCheckInterval = 0
+	config.EnableMetrics = false
+	config.MetricsProvider = NewMetricsCollector()
+	if provider := NewWithConfig(config).Metrics(); provider != nil {
+		t.Errorf("Metrics() = %T with metrics disabled, want nil", provider)
+	}
+}
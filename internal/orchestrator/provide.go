@@ -0,0 +1,345 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/AnasImloul/go-orchestrator/internal/di"
+)
+
+// provide creates a service definition for a typed factory. create resolves the factory parameters one by one
+// and calls the factory directly, so that no reflection call is needed per resolution. The parameter types are
+// still read from the factory once, to feed the lifecycle dependencies and support WithParamName.
+// The cleanup function returned by create, if any, is registered with the owner of the instance.
+func provide[T any](factory interface{}, lifetime Lifetime, create func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error)) *TypedServiceDefinition[T] {
+	if reflect.ValueOf(factory).IsNil() {
+		panic("factory must not be nil")
+	}
+
+	serviceType := reflect.TypeOf((*T)(nil)).Elem()
+	paramNames := make(map[int]string)
+
+	serviceDef := &TypedServiceDefinition[T]{
+		Name: inferServiceNameFromType(serviceType),
+		Service: TypedServiceConfig[T]{
+			Type: serviceType,
+			Factory: func(ctx context.Context, container *Container) (T, error) {
+				instance, cleanup, err := create(ctx, container, paramNames)
+				di.AddCleanup(ctx, cleanup)
+				return instance, err
+			},
+			Lifetime: lifetime,
+		},
+	}
+	wireStart(serviceDef)
+
+	// Automatically discover and add dependencies based on factory parameters
+	serviceDef.paramNames = paramNames
+	autoDiscoverDependenciesTyped(serviceDef, factory)
+
+	return serviceDef
+}
+
+// resolveParam resolves the factory parameter of type A at the given index.
+func resolveParam[A any](ctx context.Context, container *Container, paramNames map[int]string, index int) (A, error) {
+	var zero A
+	paramType := reflect.TypeOf((*A)(nil)).Elem()
+	value, err := resolveDependency(ctx, container, paramType, binding{name: paramNames[index]})
+	if err != nil {
+		return zero, fmt.Errorf("failed to resolve dependency %d (%s): %w", index, paramType.String(), err)
+	}
+	param, ok := value.Interface().(A)
+	if !ok && !(value.Kind() == reflect.Interface && value.IsNil()) {
+		return zero, fmt.Errorf("failed to resolve dependency %d (%s): resolved %s is not assignable to it", index, paramType.String(), value.Type().String())
+	}
+	// Nil interface values leave the zero value
+	return param, nil
+}
+
+// provide0 creates the service definition of Provide0, Provide0NoError and Provide0WithCleanup,
+// which call the factory through call.
+func provide0[T any](factory interface{}, lifetime Lifetime, call func() (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		return call()
+	})
+}
+
+// Provide0 is Provide1 for factories without dependencies.
+func Provide0[T any](factory func() (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide0(factory, lifetime, func() (T, func(), error) {
+		instance, err := factory()
+		return instance, nil, err
+	})
+}
+
+// Provide0NoError is Provide0 for factories that cannot fail.
+func Provide0NoError[T any](factory func() T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide0(factory, lifetime, func() (T, func(), error) {
+		return factory(), nil, nil
+	})
+}
+
+// Provide0WithCleanup is Provide1WithCleanup for factories without dependencies.
+func Provide0WithCleanup[T any](factory func() (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide0(factory, lifetime, factory)
+}
+
+// provide1 creates the service definition of Provide1, Provide1NoError and Provide1WithCleanup,
+// which call the factory through call after resolving its dependencies.
+func provide1[A, T any](factory interface{}, lifetime Lifetime, call func(A) (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		var zero T
+		a, err := resolveParam[A](ctx, container, paramNames, 0)
+		if err != nil {
+			return zero, nil, err
+		}
+		return call(a)
+	})
+}
+
+// Provide1 creates a service definition from a factory taking one dependency, such as a constructor.
+// Unlike NewStructFactory, the factory signature is checked at compile time and the factory is called
+// without reflection. Dependencies are resolved and discovered from the parameter types as usual.
+// If T has a Start method, it is called when the service starts.
+func Provide1[A, T any](factory func(A) (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide1(factory, lifetime, func(a A) (T, func(), error) {
+		instance, err := factory(a)
+		return instance, nil, err
+	})
+}
+
+// Provide1NoError is Provide1 for factories that cannot fail.
+func Provide1NoError[A, T any](factory func(A) T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide1(factory, lifetime, func(a A) (T, func(), error) {
+		return factory(a), nil, nil
+	})
+}
+
+// Provide1WithCleanup is Provide1 for factories that also return a cleanup function, as Wire providers do.
+// The cleanup function is called when the owner of the instance is torn down: the registry on Stop for
+// singletons and for transients resolved outside a scope, and otherwise the scope the instance was created in.
+// Cleanup functions are called in reverse creation order.
+func Provide1WithCleanup[A, T any](factory func(A) (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide1(factory, lifetime, factory)
+}
+
+// provide2 is provide1 for factories taking 2 dependencies.
+func provide2[A, B, T any](factory interface{}, lifetime Lifetime, call func(A, B) (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		var zero T
+		a, err := resolveParam[A](ctx, container, paramNames, 0)
+		if err != nil {
+			return zero, nil, err
+		}
+		b, err := resolveParam[B](ctx, container, paramNames, 1)
+		if err != nil {
+			return zero, nil, err
+		}
+		return call(a, b)
+	})
+}
+
+// Provide2 is Provide1 for factories taking 2 dependencies.
+func Provide2[A, B, T any](factory func(A, B) (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide2(factory, lifetime, func(a A, b B) (T, func(), error) {
+		instance, err := factory(a, b)
+		return instance, nil, err
+	})
+}
+
+// Provide2NoError is Provide2 for factories that cannot fail.
+func Provide2NoError[A, B, T any](factory func(A, B) T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide2(factory, lifetime, func(a A, b B) (T, func(), error) {
+		return factory(a, b), nil, nil
+	})
+}
+
+// Provide2WithCleanup is Provide1WithCleanup for factories taking 2 dependencies.
+func Provide2WithCleanup[A, B, T any](factory func(A, B) (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide2(factory, lifetime, factory)
+}
+
+// provide3 is provide1 for factories taking 3 dependencies.
+func provide3[A, B, C, T any](factory interface{}, lifetime Lifetime, call func(A, B, C) (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		var zero T
+		a, err := resolveParam[A](ctx, container, paramNames, 0)
+		if err != nil {
+			return zero, nil, err
+		}
+		b, err := resolveParam[B](ctx, container, paramNames, 1)
+		if err != nil {
+			return zero, nil, err
+		}
+		c, err := resolveParam[C](ctx, container, paramNames, 2)
+		if err != nil {
+			return zero, nil, err
+		}
+		return call(a, b, c)
+	})
+}
+
+// Provide3 is Provide1 for factories taking 3 dependencies.
+func Provide3[A, B, C, T any](factory func(A, B, C) (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide3(factory, lifetime, func(a A, b B, c C) (T, func(), error) {
+		instance, err := factory(a, b, c)
+		return instance, nil, err
+	})
+}
+
+// Provide3NoError is Provide3 for factories that cannot fail.
+func Provide3NoError[A, B, C, T any](factory func(A, B, C) T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide3(factory, lifetime, func(a A, b B, c C) (T, func(), error) {
+		return factory(a, b, c), nil, nil
+	})
+}
+
+// Provide3WithCleanup is Provide1WithCleanup for factories taking 3 dependencies.
+func Provide3WithCleanup[A, B, C, T any](factory func(A, B, C) (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide3(factory, lifetime, factory)
+}
+
+// provide4 is provide1 for factories taking 4 dependencies.
+func provide4[A, B, C, D, T any](factory interface{}, lifetime Lifetime, call func(A, B, C, D) (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		var zero T
+		a, err := resolveParam[A](ctx, container, paramNames, 0)
+		if err != nil {
+			return zero, nil, err
+		}
+		b, err := resolveParam[B](ctx, container, paramNames, 1)
+		if err != nil {
+			return zero, nil, err
+		}
+		c, err := resolveParam[C](ctx, container, paramNames, 2)
+		if err != nil {
+			return zero, nil, err
+		}
+		d, err := resolveParam[D](ctx, container, paramNames, 3)
+		if err != nil {
+			return zero, nil, err
+		}
+		return call(a, b, c, d)
+	})
+}
+
+// Provide4 is Provide1 for factories taking 4 dependencies.
+func Provide4[A, B, C, D, T any](factory func(A, B, C, D) (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide4(factory, lifetime, func(a A, b B, c C, d D) (T, func(), error) {
+		instance, err := factory(a, b, c, d)
+		return instance, nil, err
+	})
+}
+
+// Provide4NoError is Provide4 for factories that cannot fail.
+func Provide4NoError[A, B, C, D, T any](factory func(A, B, C, D) T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide4(factory, lifetime, func(a A, b B, c C, d D) (T, func(), error) {
+		return factory(a, b, c, d), nil, nil
+	})
+}
+
+// Provide4WithCleanup is Provide1WithCleanup for factories taking 4 dependencies.
+func Provide4WithCleanup[A, B, C, D, T any](factory func(A, B, C, D) (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide4(factory, lifetime, factory)
+}
+
+// provide5 is provide1 for factories taking 5 dependencies.
+func provide5[A, B, C, D, E, T any](factory interface{}, lifetime Lifetime, call func(A, B, C, D, E) (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		var zero T
+		a, err := resolveParam[A](ctx, container, paramNames, 0)
+		if err != nil {
+			return zero, nil, err
+		}
+		b, err := resolveParam[B](ctx, container, paramNames, 1)
+		if err != nil {
+			return zero, nil, err
+		}
+		c, err := resolveParam[C](ctx, container, paramNames, 2)
+		if err != nil {
+			return zero, nil, err
+		}
+		d, err := resolveParam[D](ctx, container, paramNames, 3)
+		if err != nil {
+			return zero, nil, err
+		}
+		e, err := resolveParam[E](ctx, container, paramNames, 4)
+		if err != nil {
+			return zero, nil, err
+		}
+		return call(a, b, c, d, e)
+	})
+}
+
+// Provide5 is Provide1 for factories taking 5 dependencies.
+func Provide5[A, B, C, D, E, T any](factory func(A, B, C, D, E) (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide5(factory, lifetime, func(a A, b B, c C, d D, e E) (T, func(), error) {
+		instance, err := factory(a, b, c, d, e)
+		return instance, nil, err
+	})
+}
+
+// Provide5NoError is Provide5 for factories that cannot fail.
+func Provide5NoError[A, B, C, D, E, T any](factory func(A, B, C, D, E) T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide5(factory, lifetime, func(a A, b B, c C, d D, e E) (T, func(), error) {
+		return factory(a, b, c, d, e), nil, nil
+	})
+}
+
+// Provide5WithCleanup is Provide1WithCleanup for factories taking 5 dependencies.
+func Provide5WithCleanup[A, B, C, D, E, T any](factory func(A, B, C, D, E) (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide5(factory, lifetime, factory)
+}
+
+// provide6 is provide1 for factories taking 6 dependencies.
+func provide6[A, B, C, D, E, F, T any](factory interface{}, lifetime Lifetime, call func(A, B, C, D, E, F) (T, func(), error)) *TypedServiceDefinition[T] {
+	return provide[T](factory, lifetime, func(ctx context.Context, container *Container, paramNames map[int]string) (T, func(), error) {
+		var zero T
+		a, err := resolveParam[A](ctx, container, paramNames, 0)
+		if err != nil {
+			return zero, nil, err
+		}
+		b, err := resolveParam[B](ctx, container, paramNames, 1)
+		if err != nil {
+			return zero, nil, err
+		}
+		c, err := resolveParam[C](ctx, container, paramNames, 2)
+		if err != nil {
+			return zero, nil, err
+		}
+		d, err := resolveParam[D](ctx, container, paramNames, 3)
+		if err != nil {
+			return zero, nil, err
+		}
+		e, err := resolveParam[E](ctx, container, paramNames, 4)
+		if err != nil {
+			return zero, nil, err
+		}
+		f, err := resolveParam[F](ctx, container, paramNames, 5)
+		if err != nil {
+			return zero, nil, err
+		}
+		return call(a, b, c, d, e, f)
+	})
+}
+
+// Provide6 is Provide1 for factories taking 6 dependencies.
+func Provide6[A, B, C, D, E, F, T any](factory func(A, B, C, D, E, F) (T, error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide6(factory, lifetime, func(a A, b B, c C, d D, e E, f F) (T, func(), error) {
+		instance, err := factory(a, b, c, d, e, f)
+		return instance, nil, err
+	})
+}
+
+// Provide6NoError is Provide6 for factories that cannot fail.
+func Provide6NoError[A, B, C, D, E, F, T any](factory func(A, B, C, D, E, F) T, lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide6(factory, lifetime, func(a A, b B, c C, d D, e E, f F) (T, func(), error) {
+		return factory(a, b, c, d, e, f), nil, nil
+	})
+}
+
+// Provide6WithCleanup is Provide1WithCleanup for factories taking 6 dependencies.
+func Provide6WithCleanup[A, B, C, D, E, F, T any](factory func(A, B, C, D, E, F) (T, func(), error), lifetime Lifetime) *TypedServiceDefinition[T] {
+	return provide6(factory, lifetime, factory)
+}
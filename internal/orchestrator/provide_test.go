@@ -0,0 +1,211 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type provideConfig struct{ dsn string }
+
+type provideClient struct {
+	config *provideConfig
+	reader io.Reader
+}
+
+// newProvideContainer creates a container for calling the factories of provided definitions directly
+func newProvideContainer(t *testing.T, register func(c *Container) error) *Container {
+	t.Helper()
+	config := DefaultConfig()
+	config.HealthCheckInterval = 0
+	container := NewWithConfig(config).Container()
+	if err := register(container); err != nil {
+		t.Fatalf("registering dependencies failed: %v", err)
+	}
+	return container
+}
+
+func TestProvideRejectsMismatchedDependency(t *testing.T) {
+	container := newProvideContainer(t, func(c *Container) error {
+		return c.Register(reflect.TypeOf(&provideConfig{}), func(ctx context.Context, container *Container) (interface{}, error) {
+			return "not a config", nil
+		}, Singleton)
+	})
+	serviceDef := Provide1NoError(func(config *provideConfig) *provideClient {
+		return &provideClient{config: config}
+	}, Transient)
+
+	client, err := serviceDef.Service.Factory(context.Background(), container)
+	if err == nil || !strings.Contains(err.Error(), "not assignable") {
+		t.Fatalf("factory returned %v, %v, want an error for the mismatched dependency", client, err)
+	}
+}
+
+func TestProvideAcceptsNilInterfaceDependency(t *testing.T) {
+	container := newProvideContainer(t, func(c *Container) error {
+		return c.Register(reflect.TypeOf((*io.Reader)(nil)).Elem(), func(ctx context.Context, container *Container) (interface{}, error) {
+			return nil, nil
+		}, Singleton)
+	})
+	serviceDef := Provide1NoError(func(reader io.Reader) *provideClient {
+		return &provideClient{reader: reader}
+	}, Transient)
+
+	client, err := serviceDef.Service.Factory(context.Background(), container)
+	if err != nil {
+		t.Fatalf("factory failed: %v", err)
+	}
+	if client.reader != nil {
+		t.Errorf("nil interface dependency resolved to %v, want nil", client.reader)
+	}
+}
+
+func TestProvideVariantsShareFactoryHandling(t *testing.T) {
+	container := newProvideContainer(t, func(c *Container) error {
+		return c.RegisterInstance(reflect.TypeOf(&provideConfig{}), &provideConfig{dsn: "postgres://"})
+	})
+	newClient := func(config *provideConfig) *provideClient { return &provideClient{config: config} }
+
+	tests := []struct {
+		name       string
+		serviceDef *TypedServiceDefinition[*provideClient]
+		wantErr    bool
+	}{
+		{"Provide1", Provide1(func(config *provideConfig) (*provideClient, error) { return newClient(config), nil }, Transient), false},
+		{"Provide1NoError", Provide1NoError(newClient, Transient), false},
+		{"Provide1WithCleanup", Provide1WithCleanup(func(config *provideConfig) (*provideClient, func(), error) {
+			return newClient(config), func() {}, nil
+		}, Transient), false},
+		{"Provide1 error", Provide1(func(config *provideConfig) (*provideClient, error) {
+			return nil, fmt.Errorf("connection refused")
+		}, Transient), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.serviceDef.Service.Factory(context.Background(), container)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("factory succeeded, want its error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("factory failed: %v", err)
+			}
+			if client.config.dsn != "postgres://" {
+				t.Errorf("client got config %+v, want the registered instance", client.config)
+			}
+		})
+	}
+}
+
+func TestProvidePanicsOnNilFactory(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Provide1NoError accepted a nil factory")
+		}
+	}()
+	Provide1NoError[*provideConfig, *provideClient](nil, Transient)
+}
+
+type (
+	arityA struct{}
+	arityB struct{}
+	arityC struct{}
+	arityD struct{}
+	arityE struct{}
+	arityF struct{}
+)
+
+// arityResult records the dependencies its factory was called with, in parameter order
+type arityResult struct{ params []interface{} }
+
+func TestProvideArities(t *testing.T) {
+	a, b, c, d, e, f := &arityA{}, &arityB{}, &arityC{}, &arityD{}, &arityE{}, &arityF{}
+	params := []interface{}{a, b, c, d, e, f}
+	container := newProvideContainer(t, func(container *Container) error {
+		for _, param := range params {
+			if err := container.RegisterInstance(reflect.TypeOf(param), param); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	result := func(params ...interface{}) (*arityResult, error) {
+		return &arityResult{params: append([]interface{}{}, params...)}, nil
+	}
+
+	tests := []*TypedServiceDefinition[*arityResult]{
+		Provide0(func() (*arityResult, error) { return result() }, Transient),
+		Provide1(func(a *arityA) (*arityResult, error) { return result(a) }, Transient),
+		Provide2(func(a *arityA, b *arityB) (*arityResult, error) { return result(a, b) }, Transient),
+		Provide3(func(a *arityA, b *arityB, c *arityC) (*arityResult, error) { return result(a, b, c) }, Transient),
+		Provide4(func(a *arityA, b *arityB, c *arityC, d *arityD) (*arityResult, error) {
+			return result(a, b, c, d)
+		}, Transient),
+		Provide5(func(a *arityA, b *arityB, c *arityC, d *arityD, e *arityE) (*arityResult, error) {
+			return result(a, b, c, d, e)
+		}, Transient),
+		Provide6(func(a *arityA, b *arityB, c *arityC, d *arityD, e *arityE, f *arityF) (*arityResult, error) {
+			return result(a, b, c, d, e, f)
+		}, Transient),
+	}
+	for arity, serviceDef := range tests {
+		t.Run(fmt.Sprintf("Provide%d", arity), func(t *testing.T) {
+			instance, err := serviceDef.Service.Factory(context.Background(), container)
+			if err != nil {
+				t.Fatalf("factory failed: %v", err)
+			}
+			if !reflect.DeepEqual(instance.params, params[:arity]) {
+				t.Errorf("factory was called with %v, want the registered instances in parameter order", instance.params)
+			}
+			if len(serviceDef.Dependencies) != arity {
+				t.Errorf("Dependencies = %v, want one per parameter", serviceDef.Dependencies)
+			}
+		})
+	}
+}
+
+func TestProvideReportsFailedParameter(t *testing.T) {
+	container := newProvideContainer(t, func(container *Container) error {
+		return container.RegisterInstance(reflect.TypeOf(&arityA{}), &arityA{})
+	})
+	called := false
+	serviceDef := Provide3NoError(func(a *arityA, b *arityB, c *arityC) *arityResult {
+		called = true
+		return &arityResult{}
+	}, Transient)
+
+	_, err := serviceDef.Service.Factory(context.Background(), container)
+	if err == nil || !strings.Contains(err.Error(), "dependency 1 (*orchestrator.arityB)") {
+		t.Errorf("factory returned %v, want an error for the unregistered second parameter", err)
+	}
+	if called {
+		t.Error("factory was called without all of its dependencies")
+	}
+}
+
+func TestProvideFeedsLifecycleDependencies(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(Provide0NoError(func() *arityA { return &arityA{} }, Singleton).AsNamed("primary"))
+	registry.Register(Provide0NoError(func() *arityB { return &arityB{} }, Singleton))
+	registry.Register(Provide2NoError(func(a *arityA, b *arityB) *arityResult {
+		return &arityResult{params: []interface{}{a, b}}
+	}, Singleton).WithName("result").WithParamName(0, "primary"))
+	startRegistry(t, registry)
+
+	want := []string{typeToDependencyName(reflect.TypeOf(&arityB{})), "primary"}
+	if got := dependenciesInGraph(registry, "result"); !reflect.DeepEqual(got, want) {
+		t.Errorf("dependencies = %v, want %v", got, want)
+	}
+	instance, err := ResolveStruct[*arityResult](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if len(instance.params) != 2 || instance.params[0] == nil || instance.params[1] == nil {
+		t.Errorf("factory was called with %v, want both dependencies", instance.params)
+	}
+}
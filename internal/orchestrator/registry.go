@@ -2,14 +2,19 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/AnasImloul/go-orchestrator/internal/di"
 	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
 	"github.com/AnasImloul/go-orchestrator/internal/logger"
+	"github.com/AnasImloul/go-orchestrator/internal/metrics"
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
 )
 
 // DefaultConfig returns the default application configuration.
@@ -17,13 +22,46 @@ func DefaultConfig() Config {
 	return Config{
 		StartupTimeout:      30 * time.Second,
 		ShutdownTimeout:     15 * time.Second,
+		ServiceStartTimeout: 0,
+		ServiceStopTimeout:  0,
 		HealthCheckInterval: 30 * time.Second,
 		EnableMetrics:       true,
 		EnableTracing:       false,
 		LogLevel:            slog.LevelInfo,
+		CaptiveDependencies: StrictnessWarn,
 	}
 }
 
+// NewMetricsCollector creates an in-memory metrics collector with the given latency histogram buckets in seconds.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	return metrics.NewCollector(buckets...)
+}
+
+// NewTracer creates a tracer that records spans and hands them to the exporter when they end.
+func NewTracer(exporter SpanExporter) Tracer {
+	return tracing.NewTracer(exporter)
+}
+
+// NewInMemorySpanExporter creates an exporter that keeps finished spans in memory.
+func NewInMemorySpanExporter() *InMemorySpanExporter {
+	return tracing.NewInMemoryExporter()
+}
+
+// NoopTracer returns a tracer that records nothing.
+func NoopTracer() Tracer {
+	return tracing.NoopTracer()
+}
+
+// DefaultRetryConfig returns the default retry configuration.
+func DefaultRetryConfig() RetryConfig {
+	return lifecycle.DefaultRetryConfig()
+}
+
+// DefaultRestartConfig returns the default restart configuration for the given policy.
+func DefaultRestartConfig(policy RestartPolicy) RestartConfig {
+	return lifecycle.DefaultRestartConfig(policy)
+}
+
 // New creates a new application with the default configuration.
 func New() *ServiceRegistry {
 	return NewWithConfig(DefaultConfig())
@@ -38,12 +76,26 @@ func NewWithConfig(config Config) *ServiceRegistry {
 	diConfig := di.ContainerConfig{
 		EnableValidation:    true,
 		EnableCircularCheck: true,
-		EnableInterception:  config.EnableTracing,
+		EnableInterception:  false, // Enabled once a service or registry interceptor is registered
 		DefaultLifetime:     di.Singleton,
 		MaxResolutionDepth:  50,
 		EnableMetrics:       config.EnableMetrics,
 	}
 
+	// Only collect metrics when a provider was configured, so that resolutions don't pay for a collector nobody reads
+	var metricsProvider di.MetricsProvider
+	if config.EnableMetrics && config.MetricsProvider != nil {
+		metricsProvider = config.MetricsProvider
+		diConfig.MetricsProvider = metricsProvider
+	}
+
+	// Only trace when enabled, so that a configured tracer can be switched off through the config
+	tracer := tracing.NoopTracer()
+	if config.EnableTracing && config.Tracer != nil {
+		tracer = config.Tracer
+	}
+	diConfig.Tracer = tracer
+
 	container := di.NewContainer(diConfig, appLogger)
 
 	// Register the logger in the DI container immediately for automatic injection
@@ -65,6 +117,16 @@ func NewWithConfig(config Config) *ServiceRegistry {
 	// Create lifecycle manager
 	lifecycleManager := lifecycle.NewLifecycleManager(appLogger)
 
+	// Use the configured service timeouts as defaults for every component start and stop
+	lifecycleManager.SetDefaultTimeouts(config.ServiceStartTimeout, config.ServiceStopTimeout)
+	lifecycleManager.SetStartupTimeout(config.StartupTimeout)
+	lifecycleManager.SetShutdownTimeout(config.ShutdownTimeout)
+
+	if recorder, ok := metricsProvider.(lifecycle.MetricsRecorder); ok {
+		lifecycleManager.SetMetricsRecorder(recorder)
+	}
+	lifecycleManager.SetTracer(tracer)
+
 	// Register the logger as a virtual component in the lifecycle manager
 	// This allows dependency validation to pass for services that depend on the logger
 	loggerComponent := &loggerComponent{name: loggerName}
@@ -78,6 +140,8 @@ func NewWithConfig(config Config) *ServiceRegistry {
 		services:         make(map[string]*ServiceDefinition),
 		config:           config,
 		logger:           appLogger,
+		metrics:          metricsProvider,
+		tracer:           tracer,
 	}
 }
 
@@ -93,110 +157,352 @@ func (sr *ServiceRegistry) Register(serviceDefInterface ServiceDefinitionInterfa
 		panic(fmt.Sprintf("service %s is already registered", serviceDef.Name))
 	}
 
-	sr.services[serviceDef.Name] = serviceDef
+	sr.addService(serviceDef)
 	return sr
 }
 
 // Start starts the service registry.
-func (sr *ServiceRegistry) Start(ctx context.Context) error {
+// If any service fails to start, the returned error is a *LifecycleError listing every failed service.
+func (sr *ServiceRegistry) Start(ctx context.Context) (err error) {
 	sr.mu.Lock()
 	defer sr.mu.Unlock()
 
+	ctx, span := sr.tracer.Start(ctx, "orchestrator.start",
+		tracing.Int("orchestrator.services", len(sr.services)),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	sr.logger.Info("Starting service registry")
 
-	// Register all services first
-	container := sr.Container()
-	for _, serviceDef := range sr.services {
-		for _, service := range serviceDef.Services {
-			// All services now use factories for consistent behavior
-			if service.Factory != nil {
-				// Create a wrapper factory that sets the registry reference and service name for BaseService instances
-				wrappedFactory := func(ctx context.Context, container *Container) (interface{}, error) {
-					instance, err := service.Factory(ctx, container)
-					if err != nil {
-						return instance, err
-					}
-
-					// Set registry reference and service name if the instance embeds BaseService
-					if baseService, ok := instance.(interface{ SetRegistry(*ServiceRegistry) }); ok {
-						baseService.SetRegistry(sr)
-					}
-					if baseService, ok := instance.(interface{ SetServiceName(string) }); ok {
-						serviceName := service.Name
-						if serviceName == "" {
-							serviceName = service.Type.String()
-						}
-						baseService.SetServiceName(serviceName)
-					}
-
-					return instance, nil
-				}
+	// Catch singletons capturing shorter-lived services and managed services that are not
+	// singletons before any service is created
+	serviceDefs := make([]*ServiceDefinition, 0, len(sr.order))
+	for _, name := range sr.order {
+		serviceDefs = append(serviceDefs, sr.services[name])
+	}
+	if err := sr.checkManagedLifetimes(serviceDefs...); err != nil {
+		return err
+	}
+	if err := sr.checkCaptiveDependencies(serviceDefs...); err != nil {
+		return err
+	}
 
-				if service.Name != "" {
-					// Register named service
-					if err := container.RegisterNamed(service.Name, service.Type, wrappedFactory, service.Lifetime); err != nil {
-						return fmt.Errorf("failed to register named service %s (%s): %w", service.Name, service.Type.String(), err)
-					}
-				} else {
-					// Register unnamed service
-					if err := container.Register(service.Type, wrappedFactory, service.Lifetime); err != nil {
-						return fmt.Errorf("failed to register service %s: %w", service.Type.String(), err)
-					}
-				}
-			} else {
-				return fmt.Errorf("service %s has no factory - all services must use factory-based registration", service.Type.String())
-			}
+	// Register all services first, in registration order so that group members keep it
+	for _, name := range sr.order {
+		if err := sr.registerInContainer(sr.services[name]); err != nil {
+			return err
 		}
 	}
 
 	// Register only service definitions with lifecycle methods as lifecycle components
 	// Structs without lifecycle methods are only registered in the DI container
-	for name, serviceDef := range sr.services {
-		// Check if this service definition has any lifecycle methods
-		hasLifecycle := serviceDef.Lifecycle.Start != nil || 
-						serviceDef.Lifecycle.Stop != nil || 
-						serviceDef.Lifecycle.Health != nil
-		
-		// Also check if any of the services implement the Service interface
-		implementsService := false
-		for _, service := range serviceDef.Services {
-			if service.Factory != nil {
-				// Check if the service type implements the Service interface
-				serviceType := service.Type
-				if serviceType.Implements(reflect.TypeOf((*Service)(nil)).Elem()) {
-					implementsService = true
+	for _, name := range sr.order {
+		if _, err := sr.registerComponent(sr.services[name]); err != nil {
+			return err
+		}
+	}
+
+	// Start the lifecycle manager, which bounds the startup by the configured startup timeout
+	if err := sr.lifecycleManager.Start(ctx); err != nil {
+		return err
+	}
+
+	// Keep cached health up to date in the background
+	if sr.config.HealthCheckInterval > 0 {
+		if err := sr.lifecycleManager.StartHealthMonitor(sr.config.HealthCheckInterval); err != nil {
+			sr.logger.Warn("Failed to start health monitor", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the service registry and then disposes the singletons created by the container in reverse
+// creation order, calling Dispose() error, Close() error or Close(ctx) error on the instances that have one.
+// If any service fails to stop, the returned error includes a *LifecycleError listing every failed service,
+// joined with the errors of the instances that failed to dispose.
+func (sr *ServiceRegistry) Stop(ctx context.Context) (err error) {
+	// Stop the health monitor before taking the registry lock, since transition
+	// handlers running on the monitor goroutine may call back into the registry
+	sr.lifecycleManager.StopHealthMonitor()
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	ctx, span := sr.tracer.Start(ctx, "orchestrator.stop",
+		tracing.Int("orchestrator.services", len(sr.services)),
+	)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	sr.logger.Info("Stopping service registry")
+
+	// Bound the whole shutdown by the configured shutdown timeout
+	if sr.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sr.config.ShutdownTimeout)
+		defer cancel()
+	}
+
+	err = sr.lifecycleManager.Stop(ctx)
+
+	// Dispose the singletons once no service uses them anymore, keeping the registrations for the next Start
+	if disposeErr := sr.container.DisposeInstances(ctx); disposeErr != nil {
+		err = errors.Join(err, disposeErr)
+	}
+	return err
+}
+
+// Add registers a service definition while the registry is running and starts it after its dependencies.
+// Unlike Register, it returns an error instead of panicking when the service is already registered.
+// If the registry is not running, the service is only registered and starts with the next Start.
+func (sr *ServiceRegistry) Add(ctx context.Context, serviceDefInterface ServiceDefinitionInterface) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	serviceDef := serviceDefInterface.ToServiceDefinition()
+
+	if _, exists := sr.services[serviceDef.Name]; exists {
+		return fmt.Errorf("service %s is already registered", serviceDef.Name)
+	}
+
+	if sr.lifecycleManager.GetPhase() != lifecycle.PhaseRunning {
+		sr.addService(serviceDef)
+		return nil
+	}
+
+	sr.logger.Info("Adding service to running registry", "name", serviceDef.Name)
+
+	if err := sr.checkManagedLifetimes(serviceDef); err != nil {
+		return err
+	}
+	if err := sr.checkCaptiveDependencies(serviceDef); err != nil {
+		return err
+	}
+
+	if err := sr.registerInContainer(serviceDef); err != nil {
+		sr.unregisterFromContainer(serviceDef)
+		return err
+	}
+
+	registered, err := sr.registerComponent(serviceDef)
+	if err != nil {
+		sr.unregisterFromContainer(serviceDef)
+		return err
+	}
+
+	if registered {
+		if err := sr.lifecycleManager.StartComponent(ctx, serviceDef.Name); err != nil {
+			if unregisterErr := sr.lifecycleManager.UnregisterComponent(serviceDef.Name); unregisterErr != nil {
+				sr.logger.Warn("Failed to unregister lifecycle component", "name", serviceDef.Name, "error", unregisterErr)
+			}
+			sr.unregisterFromContainer(serviceDef)
+			return fmt.Errorf("failed to start service %s: %w", serviceDef.Name, err)
+		}
+	}
+
+	sr.addService(serviceDef)
+	return nil
+}
+
+// RemoveOption configures the behavior of Remove.
+type RemoveOption func(*removeOptions)
+
+// removeOptions holds the options for Remove.
+type removeOptions struct {
+	cascade bool
+}
+
+// WithCascade makes Remove also stop and remove every service that depends on the removed service,
+// instead of refusing to remove a service with dependents.
+func WithCascade() RemoveOption {
+	return func(o *removeOptions) {
+		o.cascade = true
+	}
+}
+
+// Remove stops a service and removes it from the registry while the registry is running.
+// It refuses to remove a service that other services depend on unless WithCascade is given,
+// in which case the dependents are stopped and removed first. The singleton instances of a removed
+// service are disposed once it has stopped.
+func (sr *ServiceRegistry) Remove(ctx context.Context, name string, options ...RemoveOption) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	opts := removeOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if _, exists := sr.services[name]; !exists {
+		return fmt.Errorf("service %s is not registered", name)
+	}
+
+	dependents := sr.dependentsOf(name)
+	if len(dependents) > 0 && !opts.cascade {
+		return fmt.Errorf("cannot remove service %s: services %v depend on it", name, dependents)
+	}
+
+	return sr.removeService(ctx, name, make(map[string]bool))
+}
+
+// removeService removes a service after removing its dependents, so that a service is only
+// stopped once everything that depends on it has stopped.
+func (sr *ServiceRegistry) removeService(ctx context.Context, name string, removed map[string]bool) error {
+	if removed[name] {
+		return nil
+	}
+	removed[name] = true
+
+	for _, dependent := range sr.dependentsOf(name) {
+		if err := sr.removeService(ctx, dependent, removed); err != nil {
+			return err
+		}
+	}
+
+	sr.logger.Info("Removing service from registry", "name", name)
+
+	if _, isComponent := sr.lifecycleManager.GetComponentState(name); isComponent {
+		if sr.lifecycleManager.GetPhase() == lifecycle.PhaseRunning {
+			if err := sr.lifecycleManager.StopComponent(ctx, name); err != nil {
+				return fmt.Errorf("failed to stop service %s: %w", name, err)
+			}
+		}
+		if err := sr.lifecycleManager.UnregisterComponent(name); err != nil {
+			return fmt.Errorf("failed to remove service %s: %w", name, err)
+		}
+	}
+
+	if serviceDef, exists := sr.services[name]; exists {
+		if sr.lifecycleManager.GetPhase() == lifecycle.PhaseRunning {
+			sr.unregisterFromContainer(serviceDef)
+		}
+		delete(sr.services, name)
+		for i, registered := range sr.order {
+			if registered == name {
+				sr.order = append(sr.order[:i:i], sr.order[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// dependentsOf returns the names of registered services that depend on the named service,
+// combining the lifecycle DAG with the dependencies of services registered in the DI container only.
+func (sr *ServiceRegistry) dependentsOf(name string) []string {
+	seen := make(map[string]bool)
+	var dependents []string
+	for _, dependent := range sr.lifecycleManager.GetDependents(name) {
+		seen[dependent] = true
+		dependents = append(dependents, dependent)
+	}
+	for dependentName, serviceDef := range sr.services {
+		if seen[dependentName] {
+			continue
+		}
+		for _, dep := range sr.dependenciesOf(serviceDef) {
+			if dep == name {
+				seen[dependentName] = true
+				dependents = append(dependents, dependentName)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// dependenciesOf returns the dependencies of a service definition, including the definitions registering
+// the services bound to its named parameters, its optional dependencies that are registered, and every
+// registered service of the element type of its []T factory parameters.
+func (sr *ServiceRegistry) dependenciesOf(serviceDef *ServiceDefinition) []string {
+	if len(serviceDef.GroupDependencies) == 0 && len(serviceDef.NamedDependencies) == 0 && len(serviceDef.OptionalDependencies) == 0 {
+		return serviceDef.Dependencies
+	}
+
+	dependencies := append([]string{}, serviceDef.Dependencies...)
+	seen := make(map[string]bool, len(dependencies))
+	for _, dep := range dependencies {
+		seen[dep] = true
+	}
+	for _, name := range serviceDef.NamedDependencies {
+		// Names that no definition registers are kept as they are, so that validation reports them as missing
+		dep := name
+		if owner := sr.definitionRegistering(name); owner != "" {
+			dep = owner
+		}
+		if !seen[dep] {
+			seen[dep] = true
+			dependencies = append(dependencies, dep)
+		}
+	}
+	for _, name := range serviceDef.OptionalDependencies {
+		// Optional dependencies are dropped unless a definition or component provides them
+		dep := sr.definitionRegistering(name)
+		if dep == "" {
+			if _, isComponent := sr.lifecycleManager.GetComponentState(name); isComponent || sr.services[name] != nil {
+				dep = name
+			}
+		}
+		if dep != "" && dep != serviceDef.Name && !seen[dep] {
+			seen[dep] = true
+			dependencies = append(dependencies, dep)
+		}
+	}
+	for _, elemType := range serviceDef.GroupDependencies {
+		for _, name := range sr.order {
+			if name == serviceDef.Name || seen[name] {
+				continue
+			}
+			for _, service := range sr.services[name].Services {
+				if service.Type == elemType {
+					seen[name] = true
+					dependencies = append(dependencies, name)
 					break
 				}
 			}
 		}
-		
-		// Only register as lifecycle component if it has lifecycle methods or implements Service interface
-		if hasLifecycle || implementsService {
-			component := &serviceComponent{
-				serviceDef:      serviceDef,
-				serviceRegistry: sr,
-			}
+	}
+	return dependencies
+}
 
-			if err := sr.lifecycleManager.RegisterComponent(component); err != nil {
-				return fmt.Errorf("failed to register lifecycle component %s: %w", name, err)
+// definitionRegistering returns the name of the service definition that registers a named service
+// in the DI container, or an empty string if there is none.
+func (sr *ServiceRegistry) definitionRegistering(name string) string {
+	for _, defName := range sr.order {
+		serviceDef := sr.services[defName]
+		for _, service := range serviceDef.Services {
+			if containerName(serviceDef, service) == name {
+				return defName
 			}
-		} else {
-			// For structs without lifecycle, just log that they're registered in DI container only
-			sr.logger.Info("Struct registered in DI container only (no lifecycle)", "name", name)
 		}
 	}
+	return ""
+}
 
-	// Start the lifecycle manager
-	return sr.lifecycleManager.Start(ctx)
+// addService stores a service definition, keeping track of the registration order.
+func (sr *ServiceRegistry) addService(serviceDef *ServiceDefinition) {
+	sr.services[serviceDef.Name] = serviceDef
+	sr.order = append(sr.order, serviceDef.Name)
 }
 
-// Stop stops the service registry.
-func (sr *ServiceRegistry) Stop(ctx context.Context) error {
-	sr.mu.Lock()
-	defer sr.mu.Unlock()
+// containerFor returns the container passed to the lifecycle of a service definition.
+func (sr *ServiceRegistry) containerFor(serviceDef *ServiceDefinition) *Container {
+	return &Container{container: sr.container, owner: serviceDef}
+}
 
-	sr.logger.Info("Stopping service registry")
-	return sr.lifecycleManager.Stop(ctx)
+// containerName returns the name a service is registered under in the DI container. Group members
+// are not registered by type, so unnamed members are registered under the name of their definition.
+func containerName(serviceDef *ServiceDefinition, service ServiceConfig) string {
+	if service.Name == "" && service.Group != "" {
+		return serviceDef.Name
+	}
+	return service.Name
 }
 
 // Health returns the health status of the service registry.
@@ -209,29 +515,100 @@ func (sr *ServiceRegistry) Health(ctx context.Context) map[string]HealthStatus {
 	health := make(map[string]HealthStatus)
 
 	for name, componentHealth := range componentHealth {
-		// Convert lifecycle HealthStatus to our HealthStatusType
-		var statusType HealthStatusType
-		switch componentHealth.Status {
-		case lifecycle.HealthStatusHealthy:
-			statusType = HealthStatusHealthy
-		case lifecycle.HealthStatusDegraded:
-			statusType = HealthStatusDegraded
-		case lifecycle.HealthStatusUnhealthy:
-			statusType = HealthStatusUnhealthy
-		default:
-			statusType = HealthStatusUnknown
-		}
+		health[name] = toHealthStatus(componentHealth)
+	}
 
-		health[name] = HealthStatus{
-			Status:  statusType,
-			Message: componentHealth.Message,
-			Details: componentHealth.Details,
-		}
+	return health
+}
+
+// CachedHealth returns the most recent health status of every component without
+// invoking their Health methods. The cache is refreshed by the background health
+// monitor every Config.HealthCheckInterval, and by every call to Health.
+func (sr *ServiceRegistry) CachedHealth() map[string]HealthStatus {
+	states := sr.lifecycleManager.GetAllComponentStates()
+	health := make(map[string]HealthStatus, len(states))
+
+	for name, state := range states {
+		health[name] = toHealthStatus(state.Health)
 	}
 
 	return health
 }
 
+// OnHealthTransition registers a handler that is called whenever a service's health
+// status changes, for example from healthy to degraded.
+func (sr *ServiceRegistry) OnHealthTransition(handler func(ctx context.Context, transition HealthTransition)) *ServiceRegistry {
+	sr.lifecycleManager.AddHealthTransitionHandler(func(ctx context.Context, transition lifecycle.HealthTransition) {
+		handler(ctx, HealthTransition{
+			Service:   transition.Component,
+			Previous:  toHealthStatus(transition.Previous),
+			Current:   toHealthStatus(transition.Current),
+			Timestamp: transition.Timestamp,
+		})
+	})
+	return sr
+}
+
+// RestartService stops and restarts a service together with every service that depends on it.
+// Restarted services get fresh instances unless they were registered with a fixed instance.
+func (sr *ServiceRegistry) RestartService(ctx context.Context, name string) error {
+	return sr.lifecycleManager.RestartComponent(ctx, name, "manual restart")
+}
+
+// Restarts returns the restart history of a service.
+func (sr *ServiceRegistry) Restarts(name string) []RestartRecord {
+	state, exists := sr.lifecycleManager.GetComponentState(name)
+	if !exists {
+		return nil
+	}
+	return state.Restarts
+}
+
+// UseInterceptor adds an interceptor that applies to the creation of every service instance,
+// around the interceptors and decorators of the individual services. Interceptors added first are outermost.
+func (sr *ServiceRegistry) UseInterceptor(interceptor Interceptor) *ServiceRegistry {
+	sr.container.AddInterceptor(interceptor)
+	return sr
+}
+
+// StartupReport returns the timing profile of the last Start, including per-level wall times
+// and the critical path through the dependency graph. It returns nil before Start is called.
+func (sr *ServiceRegistry) StartupReport() *StartupReport {
+	return sr.lifecycleManager.GetStartupReport()
+}
+
+// Metrics returns the configured metrics provider, or nil if none is configured or metrics are disabled.
+func (sr *ServiceRegistry) Metrics() MetricsProvider {
+	return sr.metrics
+}
+
+// MetricsHandler returns an HTTP handler serving the metrics in the Prometheus text exposition format,
+// to be mounted on an HTTP mux of your choice. It responds with 404 unless the configured provider can be
+// exported, such as a *MetricsCollector set as Config.MetricsProvider.
+func (sr *ServiceRegistry) MetricsHandler() http.Handler {
+	if exporter, ok := sr.metrics.(interface{ Handler() http.Handler }); ok {
+		return exporter.Handler()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "metrics are not available", http.StatusNotFound)
+	})
+}
+
+// ScopeMiddleware returns an HTTP middleware that opens a scope for each request and disposes it when
+// the request ends. The scope is placed in the request context, so handlers resolve scoped services of
+// the request with ResolveTypeCtx or ResolveStructCtx on r.Context().
+func (sr *ServiceRegistry) ScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := sr.Container().CreateScope()
+		defer func() {
+			if err := scope.Dispose(); err != nil {
+				sr.logger.Warn("Failed to dispose request scope", "method", r.Method, "path", r.URL.Path, "error", err)
+			}
+		}()
+		next.ServeHTTP(w, r.WithContext(ContextWithContainer(r.Context(), scope)))
+	})
+}
+
 // Container returns the DI container.
 func (sr *ServiceRegistry) Container() *Container {
 	return &Container{container: sr.container}
@@ -257,33 +634,131 @@ func (sr *ServiceRegistry) Logger() logger.Logger {
 // without implementing their own signal handling logic.
 func (sr *ServiceRegistry) RunWithGracefulShutdown(ctx context.Context, shutdownTimeout time.Duration) error {
 	sr.logger.Info("Starting orchestrator with graceful shutdown", "shutdown_timeout", shutdownTimeout)
-	
+
 	// Start the orchestrator
 	if err := sr.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start orchestrator: %w", err)
 	}
-	
+
 	sr.logger.Info("Orchestrator started successfully, waiting for shutdown signal")
-	
+
 	// Wait for context cancellation (e.g., from signal handling)
 	<-ctx.Done()
-	
+
 	sr.logger.Info("Shutdown signal received, starting graceful shutdown")
-	
+
 	// Create shutdown context with timeout
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
-	
+
 	// Perform graceful shutdown
 	if err := sr.Stop(shutdownCtx); err != nil {
 		sr.logger.Error("Graceful shutdown completed with errors", "error", err)
 		return fmt.Errorf("graceful shutdown failed: %w", err)
 	}
-	
+
 	sr.logger.Info("Graceful shutdown completed successfully")
 	return nil
 }
 
+// registerInContainer registers the services of a service definition in the DI container.
+func (sr *ServiceRegistry) registerInContainer(serviceDef *ServiceDefinition) error {
+	container := sr.Container()
+	for _, service := range serviceDef.Services {
+		// All services now use factories for consistent behavior
+		if service.Factory == nil {
+			return fmt.Errorf("service %s has no factory - all services must use factory-based registration", service.Type.String())
+		}
+
+		// Create a wrapper factory that sets the registry reference and service name for BaseService instances
+		wrappedFactory := func(ctx context.Context, container *Container) (interface{}, error) {
+			instance, err := service.Factory(ctx, container)
+			if err != nil {
+				return instance, err
+			}
+
+			// Set registry reference and service name if the instance embeds BaseService. This is the only
+			// place they are set, so that every instance gets them however it is resolved. The name is the
+			// definition's, which BaseService looks up to find the dependencies to health check.
+			if baseService, ok := instance.(interface{ SetRegistry(*ServiceRegistry) }); ok {
+				baseService.SetRegistry(sr)
+			}
+			if baseService, ok := instance.(interface{ SetServiceName(string) }); ok {
+				baseService.SetServiceName(serviceDef.Name)
+			}
+
+			return instance, nil
+		}
+
+		var options []di.Option
+		if name := containerName(serviceDef, service); name != "" {
+			options = append(options, di.WithName(name))
+		}
+		if len(service.Interceptors) > 0 {
+			options = append(options, di.WithInterceptors(service.Interceptors...))
+		}
+		if service.Group != "" {
+			options = append(options, di.WithGroup(service.Group), di.WithPriority(service.Priority))
+		}
+
+		if err := container.register(service.Type, wrappedFactory, service.Lifetime, options...); err != nil {
+			if service.Name != "" {
+				return fmt.Errorf("failed to register named service %s (%s): %w", service.Name, service.Type.String(), err)
+			}
+			return fmt.Errorf("failed to register service %s: %w", service.Type.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// unregisterFromContainer removes the services of a service definition from the DI container.
+func (sr *ServiceRegistry) unregisterFromContainer(serviceDef *ServiceDefinition) {
+	for _, service := range serviceDef.Services {
+		if err := sr.container.Unregister(service.Type, containerName(serviceDef, service)); err != nil {
+			sr.logger.Warn("Failed to unregister service from DI container", "type", service.Type.String(), "error", err)
+		}
+	}
+}
+
+// registerComponent registers a service definition as a lifecycle component if it has lifecycle
+// methods or implements the Service interface. It reports whether a component was registered.
+func (sr *ServiceRegistry) registerComponent(serviceDef *ServiceDefinition) (bool, error) {
+	// Only register as lifecycle component if it has lifecycle methods or implements Service interface
+	if !sr.isManaged(serviceDef) {
+		// For structs without lifecycle, just log that they're registered in DI container only
+		sr.logger.Info("Struct registered in DI container only (no lifecycle)", "name", serviceDef.Name)
+		return false, nil
+	}
+
+	component := &serviceComponent{
+		serviceDef:      serviceDef,
+		serviceRegistry: sr,
+		dependencies:    sr.dependenciesOf(serviceDef),
+	}
+
+	if err := sr.lifecycleManager.RegisterComponent(component); err != nil {
+		return false, fmt.Errorf("failed to register lifecycle component %s: %w", serviceDef.Name, err)
+	}
+
+	return true, nil
+}
+
+// isManaged reports whether a service definition is registered as a lifecycle component: whether it has
+// lifecycle methods or one of its services implements the Service interface.
+func (sr *ServiceRegistry) isManaged(serviceDef *ServiceDefinition) bool {
+	if serviceDef.Lifecycle.Start != nil || serviceDef.Lifecycle.Stop != nil || serviceDef.Lifecycle.Health != nil {
+		return true
+	}
+	serviceInterface := reflect.TypeOf((*Service)(nil)).Elem()
+	for _, service := range serviceDef.Services {
+		if service.Factory != nil && service.Type.Implements(serviceInterface) {
+			return true
+		}
+	}
+	return false
+}
+
 // loggerComponent is a virtual component that represents the logger in the lifecycle manager.
 // It doesn't have any lifecycle methods since the logger doesn't need to be started/stopped.
 type loggerComponent struct {
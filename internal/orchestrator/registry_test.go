@@ -0,0 +1,480 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
+)
+
+type groupHandler interface{ Route() string }
+
+type groupUsers struct{}
+
+func (h *groupUsers) Route() string { return "/users" }
+
+type groupOrders struct{}
+
+func (h *groupOrders) Route() string { return "/orders" }
+
+// newTestRegistry creates a registry without the background health monitor
+func newTestRegistry() *ServiceRegistry {
+	config := DefaultConfig()
+	config.HealthCheckInterval = 0
+	return NewWithConfig(config)
+}
+
+// startRegistry starts the registry and stops it when the test ends
+func startRegistry(t *testing.T, registry *ServiceRegistry) {
+	t.Helper()
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = registry.Stop(context.Background()) })
+}
+
+func TestRegisterRejectsGroupMembersSharingAName(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton).AsGroup("routes"))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a second group member under the same name succeeded")
+		}
+	}()
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupOrders{} }, Singleton).AsGroup("routes"))
+}
+
+func TestAddRejectsGroupMembersSharingAName(t *testing.T) {
+	registry := newTestRegistry()
+	first := NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton).AsGroup("routes")
+	second := NewStructFactory[groupHandler](func() groupHandler { return &groupOrders{} }, Singleton).AsGroup("routes")
+
+	if err := registry.Add(context.Background(), first); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+	if err := registry.Add(context.Background(), second); err == nil {
+		t.Error("adding a second group member under the same name succeeded")
+	}
+}
+
+func TestNamedGroupMembers(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton).
+		AsGroup("routes").WithName("users"))
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupOrders{} }, Singleton).
+		AsGroup("routes").WithName("orders"))
+	startRegistry(t, registry)
+
+	handlers, err := ResolveGroup[groupHandler](registry.Container(), "routes")
+	if err != nil {
+		t.Fatalf("ResolveGroup() = %v", err)
+	}
+	var routes []string
+	for _, handler := range handlers {
+		routes = append(routes, handler.Route())
+	}
+	if len(routes) != 2 || routes[0] != "/users" || routes[1] != "/orders" {
+		t.Errorf("group resolved to %v, want [/users /orders] in registration order", routes)
+	}
+}
+
+type groupHealth struct{}
+
+func (h *groupHealth) Route() string { return "/health" }
+
+// groupRouter is built from every registered handler
+type groupRouter struct{ handlers []groupHandler }
+
+// routesOf returns the routes of the handlers in order
+func routesOf(handlers []groupHandler) string {
+	routes := make([]string, len(handlers))
+	for i, handler := range handlers {
+		routes[i] = handler.Route()
+	}
+	return strings.Join(routes, " ")
+}
+
+// registerHandlers registers the users and orders handlers in the routes group and the health handler in the admin group
+func registerHandlers(registry *ServiceRegistry) {
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupUsers{} }, Singleton).
+		AsGroup("routes").WithName("users"))
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupOrders{} }, Singleton).
+		AsGroup("routes").WithName("orders").WithPriority(10))
+	registry.Register(NewStructFactory[groupHandler](func() groupHandler { return &groupHealth{} }, Singleton).
+		AsGroup("admin").WithName("health").WithPriority(5))
+}
+
+func TestResolveGroupOrdersByPriority(t *testing.T) {
+	registry := newTestRegistry()
+	registerHandlers(registry)
+	startRegistry(t, registry)
+
+	all, err := ResolveAll[groupHandler](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveAll() = %v", err)
+	}
+	if got := routesOf(all); got != "/orders /health /users" {
+		t.Errorf("ResolveAll() = %s, want every group ordered by priority", got)
+	}
+	admin, err := ResolveGroup[groupHandler](registry.Container(), "admin")
+	if err != nil {
+		t.Fatalf("ResolveGroup() = %v", err)
+	}
+	if got := routesOf(admin); got != "/health" {
+		t.Errorf("ResolveGroup(admin) = %s, want /health", got)
+	}
+}
+
+func TestSliceParameterInjectsEveryImplementation(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*groupRouter](func(handlers []groupHandler) *groupRouter {
+		return &groupRouter{handlers: handlers}
+	}, Singleton).WithName("router"))
+	registerHandlers(registry)
+	startRegistry(t, registry)
+
+	router, err := ResolveStruct[*groupRouter](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if got := routesOf(router.handlers); got != "/orders /health /users" {
+		t.Errorf("router got handlers %s, want every handler ordered by priority", got)
+	}
+
+	// The router depends on the handlers, although they were registered after it
+	var dependencies []string
+	for _, node := range registry.Graph().Nodes {
+		if node.Name == "router" {
+			dependencies = node.Dependencies
+		}
+	}
+	if !reflect.DeepEqual(dependencies, []string{"users", "orders", "health"}) {
+		t.Errorf("router depends on %v, want every handler", dependencies)
+	}
+}
+
+// namedService records the names its embedded BaseService is given
+type namedService struct {
+	BaseService
+	names []string
+}
+
+func (s *namedService) SetServiceName(serviceName string) {
+	s.names = append(s.names, serviceName)
+	s.BaseService.SetServiceName(serviceName)
+}
+
+type namedDependency struct{ BaseService }
+
+func TestBaseServiceNamedOnceAfterDefinition(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*namedDependency](func() *namedDependency { return &namedDependency{} }, Singleton).
+		WithName("database"))
+	registry.Register(NewStructFactory[*namedService](func() *namedService { return &namedService{} }, Singleton).
+		WithName("tracker").WithDependencies("database"))
+	startRegistry(t, registry)
+
+	service, err := ResolveStruct[*namedService](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if len(service.names) != 1 || service.names[0] != "tracker" {
+		t.Errorf("service was named %v, want [tracker]", service.names)
+	}
+	if service.registry != registry {
+		t.Error("service was not given the registry")
+	}
+
+	// The definition name finds the dependencies to check
+	health := service.Health(context.Background())
+	if health.Details["total_dependencies"] != 1 {
+		t.Errorf("Health() = %+v, want the dependency of the tracker definition", health)
+	}
+}
+
+func TestHealthCheckIntervalRunsHealthMonitor(t *testing.T) {
+	config := DefaultConfig()
+	config.HealthCheckInterval = time.Millisecond
+	registry := NewWithConfig(config)
+	registry.Register(NewStructFactory[*namedDependency](func() *namedDependency { return &namedDependency{} }, Singleton).
+		WithName("database"))
+	transitions := make(chan HealthTransition, 1)
+	registry.OnHealthTransition(func(ctx context.Context, transition HealthTransition) {
+		if transition.Service != "database" {
+			return
+		}
+		select {
+		case transitions <- transition:
+		default:
+		}
+	})
+	startRegistry(t, registry)
+
+	select {
+	case transition := <-transitions:
+		if transition.Current.Status != HealthStatusHealthy {
+			t.Errorf("transition = %+v, want database becoming healthy", transition)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("health monitor reported no transition")
+	}
+	if health := registry.CachedHealth()["database"]; health.Status != HealthStatusHealthy {
+		t.Errorf("CachedHealth() = %+v, want database healthy", health)
+	}
+}
+
+// lifecycleLog records the starts and stops of services
+type lifecycleLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *lifecycleLog) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *lifecycleLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.events, ", ")
+}
+
+// loggedService records its starts and stops under its name, and fails to start with startErr
+type loggedService struct {
+	BaseService
+	name     string
+	log      *lifecycleLog
+	startErr error
+}
+
+func (s *loggedService) Start(ctx context.Context) error {
+	s.log.record("start " + s.name)
+	return s.startErr
+}
+
+func (s *loggedService) Stop(ctx context.Context) error {
+	s.log.record("stop " + s.name)
+	return nil
+}
+
+type loggedDatabase struct{ loggedService }
+
+type loggedAPI struct{ loggedService }
+
+type loggedWorker struct{ loggedService }
+
+func TestAddStartsServiceWhileRunning(t *testing.T) {
+	log := &lifecycleLog{}
+	registry := newTestRegistry()
+	registry.Register(NewAutoServiceFactory[*loggedDatabase](func() *loggedDatabase {
+		return &loggedDatabase{loggedService{name: "database", log: log}}
+	}, Singleton).WithName("database"))
+	startRegistry(t, registry)
+
+	err := registry.Add(context.Background(), NewAutoServiceFactory[*loggedAPI](func() *loggedAPI {
+		return &loggedAPI{loggedService{name: "api", log: log}}
+	}, Singleton).WithName("api").WithDependencies("database"))
+	if err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+
+	if got := log.String(); got != "start database, start api" {
+		t.Errorf("services ran %q, want the added service started", got)
+	}
+	if state, ok := registry.lifecycleManager.GetComponentState("api"); !ok || state.Phase != lifecycle.PhaseRunning {
+		t.Errorf("added service is %+v, want running", state)
+	}
+	if _, err := ResolveStruct[*loggedAPI](registry.Container()); err != nil {
+		t.Errorf("ResolveStruct() = %v, want the added service", err)
+	}
+}
+
+func TestAddUndoesServiceThatFailsToStart(t *testing.T) {
+	registry := newTestRegistry()
+	startRegistry(t, registry)
+
+	err := registry.Add(context.Background(), NewAutoServiceFactory[*loggedAPI](func() *loggedAPI {
+		return &loggedAPI{loggedService{name: "api", log: &lifecycleLog{}, startErr: errors.New("port in use")}}
+	}, Singleton).WithName("api"))
+	if err == nil || !strings.Contains(err.Error(), "port in use") {
+		t.Fatalf("Add() = %v, want the start error", err)
+	}
+
+	if _, ok := registry.lifecycleManager.GetComponentState("api"); ok {
+		t.Error("service that failed to start is still a lifecycle component")
+	}
+	if _, err := ResolveStruct[*loggedAPI](registry.Container()); err == nil {
+		t.Error("service that failed to start is still resolvable")
+	}
+	if err := registry.Add(context.Background(), NewAutoServiceFactory[*loggedAPI](func() *loggedAPI {
+		return &loggedAPI{loggedService{name: "api", log: &lifecycleLog{}}}
+	}, Singleton).WithName("api")); err != nil {
+		t.Errorf("adding the service again failed: %v", err)
+	}
+}
+
+func TestRemoveStopsDependentsFirst(t *testing.T) {
+	log := &lifecycleLog{}
+	registry := newTestRegistry()
+	registry.Register(NewAutoServiceFactory[*loggedDatabase](func() *loggedDatabase {
+		return &loggedDatabase{loggedService{name: "database", log: log}}
+	}, Singleton).WithName("database"))
+	registry.Register(NewAutoServiceFactory[*loggedAPI](func() *loggedAPI {
+		return &loggedAPI{loggedService{name: "api", log: log}}
+	}, Singleton).WithName("api").WithDependencies("database"))
+	registry.Register(NewAutoServiceFactory[*loggedWorker](func() *loggedWorker {
+		return &loggedWorker{loggedService{name: "worker", log: log}}
+	}, Singleton).WithName("worker"))
+	startRegistry(t, registry)
+
+	if err := registry.Remove(context.Background(), "database"); err == nil {
+		t.Fatal("Remove() of a service with dependents succeeded without WithCascade")
+	}
+
+	log.events = nil
+	if err := registry.Remove(context.Background(), "database", WithCascade()); err != nil {
+		t.Fatalf("Remove() = %v", err)
+	}
+
+	if got := log.String(); got != "stop api, stop database" {
+		t.Errorf("services ran %q, want api stopped before database", got)
+	}
+	for _, name := range []string{"database", "api"} {
+		if _, ok := registry.lifecycleManager.GetComponentState(name); ok {
+			t.Errorf("removed service %s is still a lifecycle component", name)
+		}
+	}
+	if state, _ := registry.lifecycleManager.GetComponentState("worker"); state.Phase != lifecycle.PhaseRunning {
+		t.Errorf("unrelated worker is %s, want running", state.Phase)
+	}
+}
+
+// loggedConnection is an unmanaged singleton recording when it is closed
+type loggedConnection struct{ log *lifecycleLog }
+
+func (c *loggedConnection) Close() error {
+	c.log.record("close connection")
+	return nil
+}
+
+func TestStopDisposesSingletonsAfterStoppingServices(t *testing.T) {
+	log := &lifecycleLog{}
+	registry := newTestRegistry()
+	registry.Register(NewAutoServiceFactory[*loggedDatabase](func() *loggedDatabase {
+		return &loggedDatabase{loggedService{name: "database", log: log}}
+	}, Singleton).WithName("database"))
+	registry.Register(NewStructFactory[*loggedConnection](func() *loggedConnection {
+		return &loggedConnection{log: log}
+	}, Singleton))
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	connection, err := ResolveStruct[*loggedConnection](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+
+	if err := registry.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if got := log.String(); got != "start database, stop database, close connection" {
+		t.Errorf("services ran %q, want the connection closed once the services stopped", got)
+	}
+
+	// The registrations are kept, so resolving again creates a new singleton
+	recreated, err := ResolveStruct[*loggedConnection](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() after Stop = %v", err)
+	}
+	if recreated == connection {
+		t.Error("resolved the disposed connection after Stop")
+	}
+}
+
+// identifiedService records the lifecycle calls made on it under the number of its instance
+type identifiedService struct {
+	id  int
+	log *lifecycleLog
+}
+
+func (s *identifiedService) Start(ctx context.Context) error {
+	s.log.record(fmt.Sprintf("start %d", s.id))
+	return nil
+}
+
+func (s *identifiedService) Stop(ctx context.Context) error {
+	s.log.record(fmt.Sprintf("stop %d", s.id))
+	return nil
+}
+
+func (s *identifiedService) Health(ctx context.Context) HealthStatus {
+	s.log.record(fmt.Sprintf("health %d", s.id))
+	return HealthStatus{Status: HealthStatusHealthy}
+}
+
+func TestStopAndHealthActOnStartedInstance(t *testing.T) {
+	log := &lifecycleLog{}
+	created := 0
+	registry := newTestRegistry()
+	registry.Register(NewAutoServiceFactory[*identifiedService](func() *identifiedService {
+		created++
+		return &identifiedService{id: created, log: log}
+	}, Singleton).WithName("service"))
+
+	// Checking the health of a stopped registry creates no instance
+	registry.Health(context.Background())
+	if created != 0 {
+		t.Fatalf("Health() before Start created %d instance(s)", created)
+	}
+
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if health := registry.Health(context.Background())["service"]; health.Status != HealthStatusHealthy {
+		t.Errorf("Health() = %+v, want the health of the started instance", health)
+	}
+	if err := registry.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+
+	if got := log.String(); got != "start 1, health 1, stop 1" || created != 1 {
+		t.Errorf("lifecycle ran %q on %d instance(s), want every call on the one started instance", got, created)
+	}
+}
+
+func TestManagedServicesRequireSingleton(t *testing.T) {
+	newService := func(lifetime Lifetime) ServiceDefinitionInterface {
+		return NewAutoServiceFactory[*identifiedService](func() *identifiedService {
+			return &identifiedService{log: &lifecycleLog{}}
+		}, lifetime).WithName("service")
+	}
+	isManagedLifetime := func(err error) bool {
+		var validationErr *ValidationError
+		return errors.As(err, &validationErr) && validationErr.Problems[0].Kind == ProblemManagedLifetime
+	}
+
+	for _, lifetime := range []Lifetime{Transient, Scoped} {
+		t.Run(lifetime.String(), func(t *testing.T) {
+			registry := newTestRegistry()
+			registry.Register(newService(lifetime))
+			if err := registry.Start(context.Background()); !isManagedLifetime(err) {
+				t.Errorf("Start() = %v, want a managed lifetime problem", err)
+			}
+
+			registry = newTestRegistry()
+			startRegistry(t, registry)
+			if err := registry.Add(context.Background(), newService(lifetime)); !isManagedLifetime(err) {
+				t.Errorf("Add() = %v, want a managed lifetime problem", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// requestState is a scoped service that records whether it was closed
+type requestState struct {
+	id     int
+	closed bool
+}
+
+func (s *requestState) Close() error {
+	s.closed = true
+	return nil
+}
+
+// requestHandler is a transient service depending on the scoped request state
+type requestHandler struct{ state *requestState }
+
+// newScopedRegistry registers a scoped request state numbered in creation order and a transient handler
+func newScopedRegistry(t *testing.T) *ServiceRegistry {
+	t.Helper()
+	registry := newTestRegistry()
+	created := 0
+	registry.Register(NewStructFactory[*requestState](func() *requestState {
+		created++
+		return &requestState{id: created}
+	}, Scoped))
+	registry.Register(NewStructFactory[*requestHandler](func(state *requestState) *requestHandler {
+		return &requestHandler{state: state}
+	}, Transient))
+	startRegistry(t, registry)
+	return registry
+}
+
+func TestScopeMiddlewareOpensScopePerRequest(t *testing.T) {
+	registry := newScopedRegistry(t)
+
+	var states []*requestState
+	handler := registry.ScopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first, err := ResolveStructCtx[*requestHandler](r.Context())
+		if err != nil {
+			t.Fatalf("ResolveStructCtx() = %v", err)
+		}
+		second, err := ResolveStructCtx[*requestHandler](r.Context())
+		if err != nil {
+			t.Fatalf("ResolveStructCtx() = %v", err)
+		}
+		if first == second || first.state != second.state {
+			t.Error("transient handlers of a request don't share the scoped state")
+		}
+		if first.state.closed {
+			t.Error("scoped state closed during the request")
+		}
+		states = append(states, first.state)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	if len(states) != 2 || states[0] == states[1] {
+		t.Fatalf("requests got states %v, want one per request", states)
+	}
+	for _, state := range states {
+		if !state.closed {
+			t.Errorf("state %d was not closed when its request ended", state.id)
+		}
+	}
+}
+
+func TestContextWithContainer(t *testing.T) {
+	registry := newScopedRegistry(t)
+	scope := registry.Container().CreateScope()
+	defer scope.Dispose()
+	ctx := ContextWithContainer(context.Background(), scope)
+
+	if ContainerFromContext(ctx) != scope {
+		t.Error("ContainerFromContext() did not return the container placed in the context")
+	}
+	fromContext, err := ResolveStructCtx[*requestState](ctx)
+	if err != nil {
+		t.Fatalf("ResolveStructCtx() = %v", err)
+	}
+	fromScope, err := ResolveStruct[*requestState](scope)
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if fromContext != fromScope {
+		t.Error("resolving through the context and the scope gave different scoped instances")
+	}
+
+	if _, err := ResolveStructCtx[*requestState](context.Background()); err == nil {
+		t.Error("ResolveStructCtx() without a container in the context succeeded")
+	}
+	if _, err := ResolveTypeCtx[*requestState](ctx); err == nil {
+		t.Error("ResolveTypeCtx() of a struct type succeeded, want an interface type required")
+	}
+}
+
+func TestScopedServiceOutsideScopeIsTemporary(t *testing.T) {
+	registry := newScopedRegistry(t)
+	first, err := ResolveStruct[*requestHandler](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	second, err := ResolveStruct[*requestHandler](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if first.state == second.state {
+		t.Error("resolutions outside a scope shared the scoped state")
+	}
+	if !first.state.closed || !second.state.closed {
+		t.Error("scoped state resolved outside a scope was not disposed with its temporary scope")
+	}
+}
+
+// tenantClient is a scoped service injecting the tenant registered on its scope
+type tenantClient struct{ tenant *provideConfig }
+
+func TestScopeRegisteredInstancesOverrideInChildScopes(t *testing.T) {
+	registry := newTestRegistry()
+	registry.Register(NewStructFactory[*tenantClient](func(tenant *provideConfig) *tenantClient {
+		return &tenantClient{tenant: tenant}
+	}, Scoped))
+	startRegistry(t, registry)
+
+	scope := registry.Container().CreateScope()
+	defer scope.Dispose()
+	if err := scope.RegisterInstance(reflect.TypeOf(&provideConfig{}), &provideConfig{dsn: "acme"}); err != nil {
+		t.Fatalf("RegisterInstance() = %v", err)
+	}
+	child := scope.CreateChild()
+	if err := child.RegisterInstance(reflect.TypeOf(&provideConfig{}), &provideConfig{dsn: "globex"}); err != nil {
+		t.Fatalf("RegisterInstance() = %v", err)
+	}
+
+	childClient, err := ResolveStruct[*tenantClient](child)
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	scopeClient, err := ResolveStruct[*tenantClient](scope)
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if childClient.tenant.dsn != "globex" || scopeClient.tenant.dsn != "acme" {
+		t.Errorf("clients are for %q and %q, want each scope's own tenant", childClient.tenant.dsn, scopeClient.tenant.dsn)
+	}
+	if _, err := ResolveStruct[*tenantClient](registry.Container()); err == nil {
+		t.Error("tenant registered on a scope is resolvable outside of it")
+	}
+}
@@ -0,0 +1,215 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
+)
+
+type tracedDB struct{}
+
+func (d *tracedDB) Start(ctx context.Context) error { return nil }
+func (d *tracedDB) Stop(ctx context.Context) error  { return nil }
+
+type tracedAPI struct {
+	db       *tracedDB
+	startErr error
+}
+
+func (a *tracedAPI) Start(ctx context.Context) error { return a.startErr }
+func (a *tracedAPI) Stop(ctx context.Context) error  { return nil }
+
+// newTracedRegistry creates a registry that records spans in the returned exporter, with an API
+// service depending on a DB service. The API's Start fails with startErr.
+func newTracedRegistry(startErr error) (*ServiceRegistry, *InMemorySpanExporter) {
+	exporter := NewInMemorySpanExporter()
+	config := DefaultConfig()
+	config.EnableTracing = true
+	config.Tracer = NewTracer(exporter)
+	config.HealthCheckInterval = 0
+
+	registry := NewWithConfig(config)
+	registry.Register(NewAutoServiceFactory[*tracedDB](func() *tracedDB {
+		return &tracedDB{}
+	}, Singleton))
+	registry.Register(NewAutoServiceFactory[*tracedAPI](func(db *tracedDB) *tracedAPI {
+		return &tracedAPI{db: db, startErr: startErr}
+	}, Singleton))
+	return registry, exporter
+}
+
+// findSpan returns the first span with the given name whose attributes include the given ones
+func findSpan(t *testing.T, spans []tracing.SpanData, name string, attrs map[string]interface{}) tracing.SpanData {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name != name {
+			continue
+		}
+		matches := true
+		for key, value := range attrs {
+			if span.Attributes[key] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return span
+		}
+	}
+	t.Fatalf("no %s span with attributes %v", name, attrs)
+	return tracing.SpanData{}
+}
+
+// assertChildOf checks that a span is a direct child of parent in the same trace
+func assertChildOf(t *testing.T, span, parent tracing.SpanData) {
+	t.Helper()
+	if span.ParentSpanID != parent.SpanID || span.TraceID != parent.TraceID {
+		t.Errorf("%s span %v is not a child of %s span %v", span.Name, span.Attributes, parent.Name, parent.Attributes)
+	}
+}
+
+func TestTracingStartAndStop(t *testing.T) {
+	registry, exporter := newTracedRegistry(nil)
+	dbName := typeToDependencyName(reflect.TypeOf(&tracedDB{}))
+
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	spans := exporter.Spans()
+
+	start := findSpan(t, spans, "orchestrator.start", nil)
+	if start.ParentSpanID != "" {
+		t.Errorf("orchestrator.start has parent %q, want a root span", start.ParentSpanID)
+	}
+	if start.Status != tracing.StatusOK {
+		t.Errorf("orchestrator.start has status %s, want Ok", start.Status)
+	}
+
+	lifecycleStart := findSpan(t, spans, "lifecycle.start", nil)
+	assertChildOf(t, lifecycleStart, start)
+
+	// Components start within the span of their level
+	dbStart := findSpan(t, spans, "lifecycle.component.start", map[string]interface{}{"lifecycle.component": dbName})
+	var dbLevel tracing.SpanData
+	for _, span := range spans {
+		if span.SpanID == dbStart.ParentSpanID {
+			dbLevel = span
+		}
+	}
+	if dbLevel.Name != "lifecycle.start.level" {
+		t.Fatalf("component start has parent %q, want lifecycle.start.level", dbLevel.Name)
+	}
+	assertChildOf(t, dbLevel, lifecycleStart)
+
+	// The instance started by a component is resolved within its start span
+	dbType := reflect.TypeOf(&tracedDB{}).String()
+	apiType := reflect.TypeOf(&tracedAPI{}).String()
+	dbResolve := findSpan(t, spans, "di.resolve", map[string]interface{}{"di.type": dbType, "di.depth": int64(0)})
+	assertChildOf(t, dbResolve, dbStart)
+	if _, cached := dbResolve.Attributes["di.cached"]; cached {
+		t.Errorf("first resolution of %s is marked as cached", dbType)
+	}
+
+	// Dependencies resolved by a factory are nested below the resolution of the service
+	apiResolve := findSpan(t, spans, "di.resolve", map[string]interface{}{"di.type": apiType, "di.depth": int64(0)})
+	dependency := findSpan(t, spans, "di.resolve", map[string]interface{}{"di.type": dbType, "di.depth": int64(1)})
+	assertChildOf(t, dependency, apiResolve)
+	if dependency.Attributes["di.cached"] != true {
+		t.Errorf("dependency %s was created again instead of reusing the started singleton", dbType)
+	}
+
+	for _, span := range spans {
+		if span.TraceID != start.TraceID {
+			t.Errorf("%s span is not in the startup trace", span.Name)
+		}
+		if span.Status != tracing.StatusOK {
+			t.Errorf("%s span has status %s %q, want Ok", span.Name, span.Status, span.StatusDescription)
+		}
+	}
+
+	exporter.Reset()
+	if err := registry.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	spans = exporter.Spans()
+
+	stop := findSpan(t, spans, "orchestrator.stop", nil)
+	if stop.ParentSpanID != "" || stop.TraceID == start.TraceID {
+		t.Errorf("orchestrator.stop is not the root of a trace of its own")
+	}
+	lifecycleStop := findSpan(t, spans, "lifecycle.stop", nil)
+	assertChildOf(t, lifecycleStop, stop)
+	dbStop := findSpan(t, spans, "lifecycle.component.stop", map[string]interface{}{"lifecycle.component": dbName})
+	if dbStop.TraceID != stop.TraceID || dbStop.Status != tracing.StatusOK {
+		t.Errorf("component stop span is not an Ok span of the shutdown trace")
+	}
+}
+
+func TestTracingRecordsStartFailures(t *testing.T) {
+	registry, exporter := newTracedRegistry(errors.New("port in use"))
+	apiName := typeToDependencyName(reflect.TypeOf(&tracedAPI{}))
+
+	if err := registry.Start(context.Background()); err == nil {
+		t.Fatal("Start succeeded, want the API start failure")
+	}
+	spans := exporter.Spans()
+
+	apiStart := findSpan(t, spans, "lifecycle.component.start", map[string]interface{}{"lifecycle.component": apiName})
+	if apiStart.Status != tracing.StatusError || apiStart.StatusDescription != "port in use" {
+		t.Errorf("failed component start has status %s %q, want Error %q", apiStart.Status, apiStart.StatusDescription, "port in use")
+	}
+	if len(apiStart.Events) != 1 || apiStart.Events[0].Name != "exception" {
+		t.Errorf("failed component start has events %+v, want one exception event", apiStart.Events)
+	}
+
+	// The failure propagates to every enclosing span
+	for _, name := range []string{"lifecycle.start", "orchestrator.start"} {
+		if span := findSpan(t, spans, name, nil); span.Status != tracing.StatusError {
+			t.Errorf("%s span has status %s, want Error", name, span.Status)
+		}
+	}
+
+	// Components rolled back after the failure are stopped within the startup trace
+	dbName := typeToDependencyName(reflect.TypeOf(&tracedDB{}))
+	rollback := findSpan(t, spans, "lifecycle.component.stop", map[string]interface{}{"lifecycle.component": dbName})
+	assertChildOf(t, rollback, findSpan(t, spans, "lifecycle.start", nil))
+}
+
+func TestTracingResolutionWithinCallerSpan(t *testing.T) {
+	registry, exporter := newTracedRegistry(nil)
+	tracer := NewTracer(exporter)
+
+	// Services are registered with the container on Start
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { _ = registry.Stop(context.Background()) })
+	exporter.Reset()
+
+	ctx, request := tracer.Start(context.Background(), "request")
+	if _, err := registry.Container().ResolveContext(ctx, reflect.TypeOf(&tracedAPI{})); err != nil {
+		t.Fatalf("ResolveContext failed: %v", err)
+	}
+	type unregistered struct{}
+	if _, err := registry.Container().ResolveContext(ctx, reflect.TypeOf(unregistered{})); err == nil {
+		t.Fatal("resolving an unregistered type succeeded")
+	}
+	request.End()
+	spans := exporter.Spans()
+
+	parent := findSpan(t, spans, "request", nil)
+	resolved := findSpan(t, spans, "di.resolve", map[string]interface{}{"di.type": reflect.TypeOf(&tracedAPI{}).String()})
+	assertChildOf(t, resolved, parent)
+	if resolved.Status != tracing.StatusOK {
+		t.Errorf("resolution has status %s, want Ok", resolved.Status)
+	}
+
+	missing := findSpan(t, spans, "di.resolve", map[string]interface{}{"di.type": reflect.TypeOf(unregistered{}).String()})
+	assertChildOf(t, missing, parent)
+	if missing.Status != tracing.StatusError {
+		t.Errorf("failed resolution has status %s, want Error", missing.Status)
+	}
+}
@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
@@ -12,34 +13,108 @@ import (
 	"github.com/AnasImloul/go-orchestrator/internal/di"
 	"github.com/AnasImloul/go-orchestrator/internal/lifecycle"
 	"github.com/AnasImloul/go-orchestrator/internal/logger"
+	"github.com/AnasImloul/go-orchestrator/internal/metrics"
+	"github.com/AnasImloul/go-orchestrator/internal/tracing"
 )
 
 // ServiceDefinition represents a declarative service configuration.
 type ServiceDefinition struct {
-	Name         string
-	Dependencies []string
-	Services     []ServiceConfig
-	Lifecycle    LifecycleConfig
-	RetryConfig  *lifecycle.RetryConfig
-	Metadata     map[string]string
+	Name                 string
+	Dependencies         []string
+	GroupDependencies    []reflect.Type // Element types of []T factory parameters; every service of T becomes a dependency
+	NamedDependencies    []string       // Names bound to factory parameters; the definitions registering them become dependencies
+	OptionalDependencies []string       // Dependencies that only apply if the service is registered
+	Services             []ServiceConfig
+	Lifecycle            LifecycleConfig
+	RetryConfig          *lifecycle.RetryConfig
+	RestartConfig        *lifecycle.RestartConfig
+	StartTimeout         time.Duration
+	StopTimeout          time.Duration
+	Metadata             map[string]string
+
+	params []param // Factory parameters and injected fields, for validation
 }
 
 // ServiceConfig represents a service registration configuration.
 type ServiceConfig struct {
-	Name     string
-	Type     reflect.Type
-	Factory  func(ctx context.Context, container *Container) (interface{}, error)
-	Lifetime Lifetime
+	Name         string
+	Type         reflect.Type
+	Factory      func(ctx context.Context, container *Container) (interface{}, error)
+	Lifetime     Lifetime
+	Interceptors []Interceptor
+	Group        string
+	Priority     int
 }
 
 // TypedServiceConfig represents a type-safe service registration configuration.
 type TypedServiceConfig[T any] struct {
-	Name     string
-	Type     reflect.Type
-	Factory  func(ctx context.Context, container *Container) (T, error)
-	Lifetime Lifetime
+	Name         string
+	Type         reflect.Type
+	Factory      func(ctx context.Context, container *Container) (T, error)
+	Lifetime     Lifetime
+	Interceptors []Interceptor
+	Group        string
+	Priority     int
 }
 
+// Interceptor intercepts the creation of service instances. It is called whenever the container
+// creates a new instance: once for a singleton, once per scope for a scoped service and on every
+// resolution of a transient service. Calling next creates the instance, or runs the next interceptor.
+//
+// Interceptors run outermost first: registry-wide interceptors added with UseInterceptor wrap
+// the interceptors and decorators of a service, and within each group the one added first is
+// outermost. It runs first and sees the instance last.
+type Interceptor = di.Interceptor
+
+// InterceptorFunc is a function adapter for Interceptor.
+type InterceptorFunc = di.InterceptorFunc
+
+// RetryConfig configures retry behavior for service lifecycle operations.
+type RetryConfig = lifecycle.RetryConfig
+
+// RestartPolicy determines when a failed service is restarted automatically.
+type RestartPolicy = lifecycle.RestartPolicy
+
+// RestartConfig configures automatic restarts of a failed service.
+type RestartConfig = lifecycle.RestartConfig
+
+// RestartRecord records a restart of a service.
+type RestartRecord = lifecycle.RestartRecord
+
+// TimeoutError is returned when a service's Start or Stop call overruns its timeout.
+type TimeoutError = lifecycle.TimeoutError
+
+// LifecycleError reports every service that failed during startup or shutdown.
+// It supports errors.Is and errors.As over all service errors, like errors.Join.
+type LifecycleError = lifecycle.LifecycleError
+
+// RollbackReport describes the services stopped while rolling back a failed startup.
+type RollbackReport = lifecycle.RollbackReport
+
+// RollbackEntry describes the rollback of a single service.
+type RollbackEntry = lifecycle.RollbackEntry
+
+// StartupReport is the timing profile of a startup.
+type StartupReport = lifecycle.StartupReport
+
+// LevelTiming is the timing of a single dependency level during startup.
+type LevelTiming = lifecycle.LevelTiming
+
+// ComponentTiming is the startup timing of a single service.
+type ComponentTiming = lifecycle.ComponentTiming
+
+// CriticalPath is the longest chain of dependent services by start duration.
+type CriticalPath = lifecycle.CriticalPath
+
+const (
+	// RestartNever never restarts the service automatically
+	RestartNever = lifecycle.RestartNever
+	// RestartOnFailure restarts the service when it reports unhealthy or fails to start
+	RestartOnFailure = lifecycle.RestartOnFailure
+	// RestartAlways restarts the service whenever it is not running while the registry is running
+	RestartAlways = lifecycle.RestartAlways
+)
+
 // Lifetime represents the service lifetime.
 type Lifetime int
 
@@ -52,6 +127,20 @@ const (
 	Singleton
 )
 
+// String returns the string representation of the lifetime.
+func (l Lifetime) String() string {
+	switch l {
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	case Singleton:
+		return "singleton"
+	default:
+		return "unknown"
+	}
+}
+
 // LifecycleConfig represents a service lifecycle configuration.
 type LifecycleConfig struct {
 	Start  func(ctx context.Context, container *Container) error
@@ -96,6 +185,14 @@ type HealthStatus struct {
 	Details map[string]interface{}
 }
 
+// HealthTransition describes a change in a service's health status observed by a health check.
+type HealthTransition struct {
+	Service   string
+	Previous  HealthStatus
+	Current   HealthStatus
+	Timestamp time.Time
+}
+
 // Service represents a service that can be managed by the orchestrator.
 // All services MUST implement this interface for automatic lifecycle management.
 type Service interface {
@@ -230,6 +327,7 @@ func (b *BaseService) Health(ctx context.Context) HealthStatus {
 type Container struct {
 	container di.Container
 	scope     di.Scope
+	owner     *ServiceDefinition // Definition whose lifecycle uses the container, to resolve its own group members
 }
 
 // ServiceRegistry represents the main service registry for dependency injection and lifecycle management.
@@ -238,25 +336,110 @@ type ServiceRegistry struct {
 	container        di.Container
 	lifecycleManager lifecycle.LifecycleManager
 	services         map[string]*ServiceDefinition
+	order            []string // Service names in registration order
 	config           Config
 	logger           logger.Logger
+	metrics          di.MetricsProvider
+	tracer           tracing.Tracer
 	mu               sync.RWMutex
 }
 
 // Config holds configuration for the application.
 type Config struct {
-	StartupTimeout      time.Duration
-	ShutdownTimeout     time.Duration
+	// StartupTimeout and ShutdownTimeout bound the whole of Start and Stop. Zero disables the bound.
+	StartupTimeout  time.Duration
+	ShutdownTimeout time.Duration
+
+	// ServiceStartTimeout and ServiceStopTimeout bound a single service's Start and Stop call, unless the
+	// service sets its own with WithStartTimeout or WithStopTimeout. Zero, the default, disables the bound.
+	ServiceStartTimeout time.Duration
+	ServiceStopTimeout  time.Duration
+
 	HealthCheckInterval time.Duration
 	EnableMetrics       bool
 	EnableTracing       bool
 	LogLevel            slog.Level
+
+	// MetricsProvider receives metrics when EnableMetrics is set. If nil, no metrics are
+	// collected; set it to NewMetricsCollector() for the in-memory collector. Providers that
+	// also implement LifecycleMetricsRecorder receive lifecycle metrics.
+	MetricsProvider MetricsProvider
+
+	// Tracer creates spans for startup, shutdown and resolution when EnableTracing is set.
+	// If nil, tracing is a no-op.
+	Tracer Tracer
+
+	// CaptiveDependencies determines how Start, Add and Validate handle singleton services that capture
+	// a scoped or transient service for their whole lifetime. DefaultConfig warns about them.
+	CaptiveDependencies Strictness
 }
 
+// Strictness determines how the registry handles a wiring problem it detects.
+type Strictness int
+
+const (
+	// StrictnessIgnore ignores the problem
+	StrictnessIgnore Strictness = iota
+	// StrictnessWarn logs a warning and carries on
+	StrictnessWarn
+	// StrictnessError fails with a *ValidationError
+	StrictnessError
+)
+
+// MetricsProvider receives dependency injection metrics.
+type MetricsProvider = di.MetricsProvider
+
+// LifecycleMetricsRecorder receives service lifecycle metrics.
+type LifecycleMetricsRecorder = lifecycle.MetricsRecorder
+
+// Tracer creates spans. Its shape follows OpenTelemetry's tracer, so it can be adapted to one.
+type Tracer = tracing.Tracer
+
+// Span is a single traced operation.
+type Span = tracing.Span
+
+// SpanContext identifies a span within a trace.
+type SpanContext = tracing.SpanContext
+
+// SpanAttribute is a key/value pair describing a span.
+type SpanAttribute = tracing.Attribute
+
+// SpanStatusCode is the status of a span.
+type SpanStatusCode = tracing.StatusCode
+
+// SpanData is a finished span as handed to an exporter.
+type SpanData = tracing.SpanData
+
+// SpanEvent is something that happened during a span, such as a recorded error.
+type SpanEvent = tracing.Event
+
+// SpanExporter receives finished spans.
+type SpanExporter = tracing.Exporter
+
+// InMemorySpanExporter keeps finished spans in memory, for tests and offline inspection.
+type InMemorySpanExporter = tracing.InMemoryExporter
+
+const (
+	// SpanStatusUnset is the default span status
+	SpanStatusUnset = tracing.StatusUnset
+	// SpanStatusError indicates the traced operation failed
+	SpanStatusError = tracing.StatusError
+	// SpanStatusOK indicates the traced operation succeeded
+	SpanStatusOK = tracing.StatusOK
+)
+
+// MetricsCollector is the built-in in-memory metrics backend with a Prometheus exporter.
+type MetricsCollector = metrics.Collector
+
 // serviceComponent wraps a service definition as a lifecycle component.
+// It holds the instances it started, so that Stop and Health act on the very instances that were started.
 type serviceComponent struct {
 	serviceDef      *ServiceDefinition
 	serviceRegistry *ServiceRegistry
+	dependencies    []string
+
+	mu        sync.RWMutex
+	instances []interface{} // Instances of the services with a factory, resolved by Start
 }
 
 func (c *serviceComponent) Name() string {
@@ -264,14 +447,30 @@ func (c *serviceComponent) Name() string {
 }
 
 func (c *serviceComponent) Dependencies() []string {
-	return c.serviceDef.Dependencies
+	return c.dependencies
 }
 
 func (c *serviceComponent) Start(ctx context.Context) error {
 	// Services are already registered in ServiceRegistry.Start()
-	// Just start the lifecycle
+	// Resolve the instances to manage, then start the lifecycle
+	container := c.serviceRegistry.containerFor(c.serviceDef)
+	instances := make([]interface{}, 0, len(c.serviceDef.Services))
+	for _, service := range c.serviceDef.Services {
+		if service.Factory == nil {
+			continue
+		}
+		instance, err := container.ResolveContext(ctx, service.Type)
+		if err != nil {
+			return fmt.Errorf("failed to resolve service %s: %w", service.Type.String(), err)
+		}
+		instances = append(instances, instance)
+	}
+
+	c.mu.Lock()
+	c.instances = instances
+	c.mu.Unlock()
+
 	if c.serviceDef.Lifecycle.Start != nil {
-		container := c.serviceRegistry.Container()
 		return c.serviceDef.Lifecycle.Start(ctx, container)
 	}
 
@@ -283,46 +482,21 @@ func (c *serviceComponent) Stop(ctx context.Context) error {
 		return c.serviceDef.Lifecycle.Stop(ctx)
 	}
 
-	// If no Stop function is provided, try to resolve the service and call its Stop method directly
-	// This handles the case where factory services have nil Stop functions
-	container := c.serviceRegistry.Container()
-	for _, service := range c.serviceDef.Services {
-		if service.Factory != nil {
-			instance, err := service.Factory(ctx, container)
-			if err == nil {
-				// Set registry reference and service name if the instance embeds BaseService
-				if baseService, ok := instance.(interface{ SetRegistry(*ServiceRegistry) }); ok {
-					baseService.SetRegistry(c.serviceRegistry)
-				}
-				if baseService, ok := instance.(interface{ SetServiceName(string) }); ok {
-					baseService.SetServiceName(c.serviceDef.Name)
-				}
-
-				if service, ok := instance.(Service); ok {
-					return service.Stop(ctx)
-				}
+	// If no Stop function is provided, call the Stop method of the started instances in reverse order
+	instances := c.started()
+	var errs []error
+	for i := len(instances) - 1; i >= 0; i-- {
+		if stoppable, ok := instances[i].(interface{ Stop(context.Context) error }); ok {
+			if err := stoppable.Stop(ctx); err != nil {
+				errs = append(errs, err)
 			}
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (c *serviceComponent) Health(ctx context.Context) lifecycle.ComponentHealth {
 	if c.serviceDef.Lifecycle.Health != nil {
-		// Before calling the health function, ensure any BaseService instances have registry access
-		container := c.serviceRegistry.Container()
-		for _, service := range c.serviceDef.Services {
-			if service.Factory != nil {
-				instance, err := service.Factory(ctx, container)
-				if err == nil {
-					// Set registry reference if the instance embeds BaseService
-					if baseService, ok := instance.(interface{ SetRegistry(*ServiceRegistry) }); ok {
-						baseService.SetRegistry(c.serviceRegistry)
-					}
-				}
-			}
-		}
-
 		status := c.serviceDef.Lifecycle.Health(ctx)
 		return lifecycle.ComponentHealth{
 			Status:    mapHealthStatus(status.Status),
@@ -332,128 +506,155 @@ func (c *serviceComponent) Health(ctx context.Context) lifecycle.ComponentHealth
 		}
 	}
 
-	// If no Health function is provided, try to resolve the service and call its Health method directly
-	// This handles the case where factory services have nil Health functions
-	container := c.serviceRegistry.Container()
-	for _, service := range c.serviceDef.Services {
-		if service.Factory != nil {
-			instance, err := service.Factory(ctx, container)
-			if err == nil {
-				// Set registry reference and service name if the instance embeds BaseService
-				if baseService, ok := instance.(interface{ SetRegistry(*ServiceRegistry) }); ok {
-					baseService.SetRegistry(c.serviceRegistry)
-				}
-				if baseService, ok := instance.(interface{ SetServiceName(string) }); ok {
-					baseService.SetServiceName(c.serviceDef.Name)
-				}
+	// If no Health function is provided, call the Health method of the started instances
+	instances := c.started()
+	for _, instance := range instances {
+		if checkable, ok := instance.(interface {
+			Health(context.Context) HealthStatus
+		}); ok {
+			healthStatus := checkable.Health(ctx)
+			return lifecycle.ComponentHealth{
+				Status:    mapHealthStatus(healthStatus.Status),
+				Message:   healthStatus.Message,
+				Details:   healthStatus.Details,
+				Timestamp: time.Now(),
+			}
+		}
+	}
 
-				// Check if service implements Service interface
-				if service, ok := instance.(Service); ok {
-					// Service implements Service interface, call its Health method
-					healthStatus := service.Health(ctx)
-					return lifecycle.ComponentHealth{
-						Status:    mapHealthStatus(healthStatus.Status),
-						Message:   healthStatus.Message,
-						Details:   healthStatus.Details,
-						Timestamp: time.Now(),
-					}
-				}
+	// Started instances without a Health method get automatic default behavior
+	if len(instances) > 0 {
+		return c.autoHealth()
+	}
 
-				// Service doesn't implement Service interface, provide automatic default behavior
-				dependencies := c.serviceDef.Dependencies
+	return lifecycle.ComponentHealth{
+		Status:    lifecycle.HealthStatusHealthy,
+		Message:   "Service is healthy",
+		Timestamp: time.Now(),
+	}
+}
 
-				if len(dependencies) == 0 {
-					// No dependencies, return healthy
-					return lifecycle.ComponentHealth{
+// started returns the instances resolved by the last Start.
+func (c *serviceComponent) started() []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.instances
+}
+
+// autoHealth detects the health of a service without a Health method from the health of its dependencies.
+func (c *serviceComponent) autoHealth() lifecycle.ComponentHealth {
+	dependencies := c.dependencies
+
+	if len(dependencies) == 0 {
+		// No dependencies, return healthy
+		return lifecycle.ComponentHealth{
+			Status:    lifecycle.HealthStatusHealthy,
+			Message:   "Service is healthy (no dependencies, auto-detected)",
+			Timestamp: time.Now(),
+		}
+	} else {
+		// Has dependencies, check their health and aggregate
+		dependencyHealths := make(map[string]lifecycle.ComponentHealth)
+		overallStatus := lifecycle.HealthStatusHealthy
+		unhealthyCount := 0
+		degradedCount := 0
+
+		// Check health of each dependency
+		for _, depName := range dependencies {
+			// Get the dependency component state from the lifecycle manager
+			if depState, exists := c.serviceRegistry.lifecycleManager.GetComponentState(depName); exists {
+				// For automatic health detection, we need to get fresh health status
+				// But we need to avoid recursion. Let's use a simple approach:
+				// If the stored health is recent (within last 5 seconds), use it
+				// Otherwise, assume healthy to avoid recursion
+				depHealth := depState.Health
+
+				// Check if the health status is recent (within 5 seconds)
+				if time.Since(depHealth.Timestamp) < 5*time.Second {
+					// Use the stored health status
+					dependencyHealths[depName] = depHealth
+				} else {
+					// Health status is stale, assume healthy to avoid recursion
+					depHealth = lifecycle.ComponentHealth{
 						Status:    lifecycle.HealthStatusHealthy,
-						Message:   "Service is healthy (no dependencies, auto-detected)",
+						Message:   "Dependency health assumed healthy (stale status, recursion prevention)",
 						Timestamp: time.Now(),
 					}
-				} else {
-					// Has dependencies, check their health and aggregate
-					dependencyHealths := make(map[string]lifecycle.ComponentHealth)
-					overallStatus := lifecycle.HealthStatusHealthy
-					unhealthyCount := 0
-					degradedCount := 0
-
-					// Check health of each dependency
-					for _, depName := range dependencies {
-						// Get the dependency component state from the lifecycle manager
-						if depState, exists := c.serviceRegistry.lifecycleManager.GetComponentState(depName); exists {
-							// For automatic health detection, we need to get fresh health status
-							// But we need to avoid recursion. Let's use a simple approach:
-							// If the stored health is recent (within last 5 seconds), use it
-							// Otherwise, assume healthy to avoid recursion
-							depHealth := depState.Health
-
-							// Check if the health status is recent (within 5 seconds)
-							if time.Since(depHealth.Timestamp) < 5*time.Second {
-								// Use the stored health status
-								dependencyHealths[depName] = depHealth
-							} else {
-								// Health status is stale, assume healthy to avoid recursion
-								depHealth = lifecycle.ComponentHealth{
-									Status:    lifecycle.HealthStatusHealthy,
-									Message:   "Dependency health assumed healthy (stale status, recursion prevention)",
-									Timestamp: time.Now(),
-								}
-								dependencyHealths[depName] = depHealth
-							}
-
-							// Aggregate health status (unhealthy > degraded > healthy)
-							switch depHealth.Status {
-							case lifecycle.HealthStatusUnhealthy:
-								overallStatus = lifecycle.HealthStatusUnhealthy
-								unhealthyCount++
-							case lifecycle.HealthStatusDegraded:
-								if overallStatus != lifecycle.HealthStatusUnhealthy {
-									overallStatus = lifecycle.HealthStatusDegraded
-								}
-								degradedCount++
-							}
-						} else {
-							// Dependency not found, consider it unhealthy
-							dependencyHealths[depName] = lifecycle.ComponentHealth{
-								Status:    lifecycle.HealthStatusUnhealthy,
-								Message:   "Dependency not found",
-								Timestamp: time.Now(),
-							}
-							overallStatus = lifecycle.HealthStatusUnhealthy
-							unhealthyCount++
-						}
-					}
-
-					// Generate appropriate message based on aggregated status
-					var message string
-					switch overallStatus {
-					case lifecycle.HealthStatusUnhealthy:
-						message = fmt.Sprintf("Service unhealthy (%d/%d dependencies unhealthy, auto-detected)", unhealthyCount, len(dependencies))
-					case lifecycle.HealthStatusDegraded:
-						message = fmt.Sprintf("Service degraded (%d/%d dependencies degraded, auto-detected)", degradedCount, len(dependencies))
-					default:
-						message = fmt.Sprintf("Service healthy (all %d dependencies healthy, auto-detected)", len(dependencies))
-					}
+					dependencyHealths[depName] = depHealth
+				}
 
-					return lifecycle.ComponentHealth{
-						Status:  overallStatus,
-						Message: message,
-						Details: map[string]interface{}{
-							"auto_detected": true,
-						},
-						Timestamp: time.Now(),
+				// Aggregate health status (unhealthy > degraded > healthy)
+				switch depHealth.Status {
+				case lifecycle.HealthStatusUnhealthy:
+					overallStatus = lifecycle.HealthStatusUnhealthy
+					unhealthyCount++
+				case lifecycle.HealthStatusDegraded:
+					if overallStatus != lifecycle.HealthStatusUnhealthy {
+						overallStatus = lifecycle.HealthStatusDegraded
 					}
+					degradedCount++
+				}
+			} else {
+				// Dependency not found, consider it unhealthy
+				dependencyHealths[depName] = lifecycle.ComponentHealth{
+					Status:    lifecycle.HealthStatusUnhealthy,
+					Message:   "Dependency not found",
+					Timestamp: time.Now(),
 				}
+				overallStatus = lifecycle.HealthStatusUnhealthy
+				unhealthyCount++
 			}
 		}
-	}
 
-	return lifecycle.ComponentHealth{
-		Status:    lifecycle.HealthStatusHealthy,
-		Message:   "Service is healthy",
-		Timestamp: time.Now(),
+		// Generate appropriate message based on aggregated status
+		var message string
+		switch overallStatus {
+		case lifecycle.HealthStatusUnhealthy:
+			message = fmt.Sprintf("Service unhealthy (%d/%d dependencies unhealthy, auto-detected)", unhealthyCount, len(dependencies))
+		case lifecycle.HealthStatusDegraded:
+			message = fmt.Sprintf("Service degraded (%d/%d dependencies degraded, auto-detected)", degradedCount, len(dependencies))
+		default:
+			message = fmt.Sprintf("Service healthy (all %d dependencies healthy, auto-detected)", len(dependencies))
+		}
+
+		return lifecycle.ComponentHealth{
+			Status:  overallStatus,
+			Message: message,
+			Details: map[string]interface{}{
+				"auto_detected": true,
+			},
+			Timestamp: time.Now(),
+		}
 	}
 }
 
 func (c *serviceComponent) GetRetryConfig() *lifecycle.RetryConfig {
 	return c.serviceDef.RetryConfig
 }
+
+func (c *serviceComponent) GetRestartConfig() *lifecycle.RestartConfig {
+	return c.serviceDef.RestartConfig
+}
+
+func (c *serviceComponent) GetStartTimeout() time.Duration {
+	return c.serviceDef.StartTimeout
+}
+
+func (c *serviceComponent) GetStopTimeout() time.Duration {
+	return c.serviceDef.StopTimeout
+}
+
+// Reset discards the cached singleton instances of the service so that a restart
+// creates fresh ones. Services registered with a fixed instance keep that instance.
+func (c *serviceComponent) Reset(ctx context.Context) error {
+	c.mu.Lock()
+	c.instances = nil
+	c.mu.Unlock()
+
+	for _, service := range c.serviceDef.Services {
+		if err := c.serviceRegistry.container.Reset(service.Type, containerName(c.serviceDef, service)); err != nil {
+			return fmt.Errorf("failed to reset service %s: %w", service.Type.String(), err)
+		}
+	}
+	return nil
+}
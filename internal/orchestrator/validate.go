@@ -0,0 +1,479 @@
+package orchestrator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ProblemKind classifies a problem found by ServiceRegistry.Validate.
+type ProblemKind string
+
+const (
+	// ProblemMissingBinding is a factory parameter or injected field that no service is registered for
+	ProblemMissingBinding ProblemKind = "missing_binding"
+	// ProblemMissingDependency is a lifecycle dependency that is not a managed service, which fails startup
+	ProblemMissingDependency ProblemKind = "missing_dependency"
+	// ProblemAmbiguousBinding is a factory parameter or injected field that several services are registered for
+	ProblemAmbiguousBinding ProblemKind = "ambiguous_binding"
+	// ProblemCycle is a cycle of services that need each other to be created
+	ProblemCycle ProblemKind = "cycle"
+	// ProblemDuplicateName is a service name registered by several services
+	ProblemDuplicateName ProblemKind = "duplicate_name"
+	// ProblemCaptiveDependency is a singleton service that captures a scoped or transient service
+	ProblemCaptiveDependency ProblemKind = "captive_dependency"
+	// ProblemManagedLifetime is a lifecycle-managed service that is not a singleton, which fails startup
+	ProblemManagedLifetime ProblemKind = "managed_lifetime"
+)
+
+// ValidationProblem is a single problem found by ServiceRegistry.Validate.
+type ValidationProblem struct {
+	Kind    ProblemKind
+	Service string // Name of the service definition the problem was found in
+	Message string
+}
+
+// String returns the string representation of the problem.
+func (p ValidationProblem) String() string {
+	return fmt.Sprintf("%s: %s (%s)", p.Service, p.Message, p.Kind)
+}
+
+// ValidationError reports every problem found by ServiceRegistry.Validate.
+type ValidationError struct {
+	Problems []ValidationProblem
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		parts = append(parts, problem.String())
+	}
+	return fmt.Sprintf("validation found %d problem(s): %s", len(e.Problems), strings.Join(parts, "; "))
+}
+
+// Validate checks the wiring of every registered service without calling any factory, so that it can run
+// in unit tests or before Start. It checks the parameter types of auto-wired factories and the injected
+// fields of structs against the registered services, and reports all problems at once: missing bindings,
+// ambiguous bindings, cycles, duplicate names, and lifecycle dependencies and lifetimes that would fail startup.
+// Captive dependencies are reported unless Config.CaptiveDependencies is StrictnessIgnore.
+// It returns nil if the wiring is valid, and a *ValidationError otherwise.
+func (sr *ServiceRegistry) Validate() error {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	var problems []ValidationProblem
+	for _, name := range sr.order {
+		problems = append(problems, sr.validateService(sr.services[name])...)
+		problems = append(problems, sr.managedLifetimes(sr.services[name])...)
+		if sr.config.CaptiveDependencies != StrictnessIgnore {
+			problems = append(problems, sr.captiveDependencies(sr.services[name])...)
+		}
+	}
+	problems = append(problems, sr.duplicateNames()...)
+	problems = append(problems, sr.cycles()...)
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// validateService checks the parameters and lifecycle dependencies of a service definition.
+func (sr *ServiceRegistry) validateService(serviceDef *ServiceDefinition) []ValidationProblem {
+	var problems []ValidationProblem
+	missing := make(map[string]bool)
+
+	for _, p := range serviceDef.params {
+		serviceType, b, ok := sr.bindingTarget(p)
+		if !ok {
+			continue
+		}
+
+		problem := ValidationProblem{Service: serviceDef.Name}
+		switch {
+		case b.name != "":
+			if sr.definitionRegistering(b.name) == "" && !sr.container.ContainsByName(b.name) {
+				problem.Kind = ProblemMissingBinding
+				problem.Message = fmt.Sprintf("%s is bound to service '%s', which is not registered", serviceType.String(), b.name)
+				missing[b.name] = true
+			}
+		case isServiceSlice(serviceType) && len(sr.providersOf(serviceType)) == 0:
+			// A slice of services is injected with every service of its element type, even if there are none
+		default:
+			providers := sr.providersOf(serviceType)
+			switch {
+			case len(providers) > 1:
+				problem.Kind = ProblemAmbiguousBinding
+				problem.Message = fmt.Sprintf("%s is registered by several services (%s) and only the last one is injected", serviceType.String(), strings.Join(providers, ", "))
+			case len(providers) == 0 && !sr.container.Contains(serviceType):
+				problem.Kind = ProblemMissingBinding
+				if members := sr.groupMembersOf(serviceType); len(members) > 0 {
+					problem.Message = fmt.Sprintf("%s is only registered in groups (%s); inject []%s or bind it by name", serviceType.String(), strings.Join(members, ", "), serviceType.String())
+				} else {
+					problem.Message = fmt.Sprintf("no service is registered for %s", serviceType.String())
+				}
+				missing[typeToDependencyName(serviceType)] = true
+			}
+		}
+		if problem.Kind != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	// Managed services can only depend on other managed services, or the lifecycle manager rejects them on Start
+	if !sr.isManaged(serviceDef) {
+		return problems
+	}
+	for _, dep := range sr.dependenciesOf(serviceDef) {
+		if missing[dep] {
+			continue
+		}
+		if _, isComponent := sr.lifecycleManager.GetComponentState(dep); isComponent {
+			continue
+		}
+		dependency, exists := sr.services[dep]
+		switch {
+		case !exists:
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemMissingDependency,
+				Service: serviceDef.Name,
+				Message: fmt.Sprintf("depends on %s, which is not registered", dep),
+			})
+		case !sr.isManaged(dependency):
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemMissingDependency,
+				Service: serviceDef.Name,
+				Message: fmt.Sprintf("depends on %s, which has no lifecycle to start it before this service", dep),
+			})
+		}
+	}
+
+	return problems
+}
+
+// bindingTarget returns the service type and binding that a parameter needs to be registered, or false for
+// optional parameters, which are left absent. Lazy parameters and providers need the service they resolve.
+func (sr *ServiceRegistry) bindingTarget(p param) (reflect.Type, binding, bool) {
+	if p.binding.optional {
+		return nil, binding{}, false
+	}
+	if _, ok := asOptionalParam(p.paramType); ok {
+		return nil, binding{}, false
+	}
+	if lazy, ok := asLazyParam(p.paramType); ok {
+		return lazy.DependencyType(), p.binding, true
+	}
+	if isProvider(p.paramType) && len(sr.providersOf(p.paramType)) == 0 && !sr.container.Contains(p.paramType) {
+		return p.paramType.Out(0), p.binding, true
+	}
+	return p.paramType, p.binding, true
+}
+
+// providersOf returns the names of the service definitions that register a service of a type by type,
+// in registration order. Group members are not registered by type.
+func (sr *ServiceRegistry) providersOf(serviceType reflect.Type) []string {
+	var providers []string
+	for _, name := range sr.order {
+		for _, service := range sr.services[name].Services {
+			if service.Type == serviceType && service.Group == "" {
+				providers = append(providers, name)
+				break
+			}
+		}
+	}
+	return providers
+}
+
+// groupMembersOf returns the names of the service definitions that register a service of a type in a group.
+func (sr *ServiceRegistry) groupMembersOf(serviceType reflect.Type) []string {
+	var members []string
+	for _, name := range sr.order {
+		for _, service := range sr.services[name].Services {
+			if service.Type == serviceType && service.Group != "" {
+				members = append(members, name)
+				break
+			}
+		}
+	}
+	return members
+}
+
+// captiveDependencies reports the parameters through which a singleton service captures a scoped or transient
+// service for its whole lifetime. Providers resolve their service on every call, so they capture nothing.
+func (sr *ServiceRegistry) captiveDependencies(serviceDef *ServiceDefinition) []ValidationProblem {
+	if lifetimeOf(serviceDef) != Singleton {
+		return nil
+	}
+
+	var problems []ValidationProblem
+	for _, p := range serviceDef.params {
+		if isProvider(p.paramType) {
+			continue
+		}
+		for _, ref := range sr.paramServices(serviceDef, p) {
+			if ref.service.Lifetime == Singleton {
+				continue
+			}
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemCaptiveDependency,
+				Service: serviceDef.Name,
+				Message: fmt.Sprintf("singleton captures %s service %s through %s; inject a func() (%s, error) provider or change the lifetimes",
+					ref.service.Lifetime, ref.definition, p.paramType.String(), ref.service.Type.String()),
+			})
+		}
+	}
+	return problems
+}
+
+// checkCaptiveDependencies handles the captive dependencies of service definitions according to
+// Config.CaptiveDependencies: it logs a warning for each, or fails with a *ValidationError listing them.
+func (sr *ServiceRegistry) checkCaptiveDependencies(serviceDefs ...*ServiceDefinition) error {
+	if sr.config.CaptiveDependencies == StrictnessIgnore {
+		return nil
+	}
+
+	var problems []ValidationProblem
+	for _, serviceDef := range serviceDefs {
+		problems = append(problems, sr.captiveDependencies(serviceDef)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if sr.config.CaptiveDependencies == StrictnessError {
+		return &ValidationError{Problems: problems}
+	}
+	for _, problem := range problems {
+		sr.logger.Warn("Captive dependency", "service", problem.Service, "problem", problem.Message)
+	}
+	return nil
+}
+
+// managedLifetimes reports the services of a lifecycle-managed service definition that are not singletons.
+// The registry starts, stops and checks the health of the one instance it resolved at startup, so a scoped
+// or transient lifetime would leave the other instances of the service unmanaged.
+func (sr *ServiceRegistry) managedLifetimes(serviceDef *ServiceDefinition) []ValidationProblem {
+	if !sr.isManaged(serviceDef) {
+		return nil
+	}
+
+	var problems []ValidationProblem
+	for _, service := range serviceDef.Services {
+		if service.Factory == nil || service.Lifetime == Singleton {
+			continue
+		}
+		problems = append(problems, ValidationProblem{
+			Kind:    ProblemManagedLifetime,
+			Service: serviceDef.Name,
+			Message: fmt.Sprintf("%s service %s has a lifecycle, which requires a singleton lifetime",
+				service.Lifetime, service.Type.String()),
+		})
+	}
+	return problems
+}
+
+// checkManagedLifetimes fails with a *ValidationError listing the lifecycle-managed services of service
+// definitions that are not singletons.
+func (sr *ServiceRegistry) checkManagedLifetimes(serviceDefs ...*ServiceDefinition) error {
+	var problems []ValidationProblem
+	for _, serviceDef := range serviceDefs {
+		problems = append(problems, sr.managedLifetimes(serviceDef)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// lifetimeOf returns the longest lifetime of the services of a service definition.
+func lifetimeOf(serviceDef *ServiceDefinition) Lifetime {
+	lifetime := Transient
+	for _, service := range serviceDef.Services {
+		if service.Lifetime > lifetime {
+			lifetime = service.Lifetime
+		}
+	}
+	return lifetime
+}
+
+// duplicateNames reports service names that several services register, of which only the last one is kept.
+func (sr *ServiceRegistry) duplicateNames() []ValidationProblem {
+	registering := make(map[string][]string)
+	var names []string
+	for _, defName := range sr.order {
+		serviceDef := sr.services[defName]
+		for _, service := range serviceDef.Services {
+			name := containerName(serviceDef, service)
+			if name == "" {
+				continue
+			}
+			if _, seen := registering[name]; !seen {
+				names = append(names, name)
+			}
+			registering[name] = append(registering[name], defName)
+		}
+	}
+
+	var problems []ValidationProblem
+	for _, name := range names {
+		if definitions := registering[name]; len(definitions) > 1 {
+			problems = append(problems, ValidationProblem{
+				Kind:    ProblemDuplicateName,
+				Service: definitions[len(definitions)-1],
+				Message: fmt.Sprintf("service name '%s' is registered by %s and only the last one is kept", name, strings.Join(definitions, ", ")),
+			})
+		}
+	}
+	return problems
+}
+
+// creationDependencies returns the names of the service definitions that a service definition needs
+// to be created or started: the services its parameters resolve to and its lifecycle dependencies.
+// Lazy and provider parameters are resolved after creation, so they are left out.
+func (sr *ServiceRegistry) creationDependencies(serviceDef *ServiceDefinition) []string {
+	var dependencies []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if _, exists := sr.services[name]; exists && !seen[name] {
+			seen[name] = true
+			dependencies = append(dependencies, name)
+		}
+	}
+
+	for _, p := range serviceDef.params {
+		if _, ok := asLazyParam(p.paramType); ok || isProvider(p.paramType) {
+			continue
+		}
+		for _, ref := range sr.paramServices(serviceDef, p) {
+			add(ref.definition)
+		}
+	}
+	for _, dep := range sr.dependenciesOf(serviceDef) {
+		add(dep)
+	}
+	return dependencies
+}
+
+// serviceRef refers to a service registered by a service definition.
+type serviceRef struct {
+	definition string
+	service    ServiceConfig
+}
+
+// paramServices returns the registered services that a parameter of a service definition resolves to:
+// the service bound by name, the last service registered by type, or every service of the element type
+// of a slice. Optional and lazy parameters and providers resolve to the services they wrap.
+func (sr *ServiceRegistry) paramServices(serviceDef *ServiceDefinition, p param) []serviceRef {
+	serviceType, b := p.paramType, p.binding
+	if optional, ok := asOptionalParam(serviceType); ok {
+		serviceType = optional.DependencyType()
+	} else if lazy, ok := asLazyParam(serviceType); ok {
+		serviceType = lazy.DependencyType()
+	} else if isProvider(serviceType) && len(sr.providersOf(serviceType)) == 0 && !sr.container.Contains(serviceType) {
+		serviceType = serviceType.Out(0)
+	}
+
+	var refs []serviceRef
+	find := func(definition string, matches func(service ServiceConfig) bool) {
+		serviceDef := sr.services[definition]
+		if serviceDef == nil {
+			return
+		}
+		for _, service := range serviceDef.Services {
+			if matches(service) {
+				refs = append(refs, serviceRef{definition: definition, service: service})
+				return
+			}
+		}
+	}
+
+	switch {
+	case b.name != "":
+		owner := sr.definitionRegistering(b.name)
+		find(owner, func(service ServiceConfig) bool { return containerName(sr.services[owner], service) == b.name })
+	case isServiceSlice(serviceType) && len(sr.providersOf(serviceType)) == 0:
+		elemType := serviceType.Elem()
+		for _, name := range sr.order {
+			if name != serviceDef.Name {
+				find(name, func(service ServiceConfig) bool { return service.Type == elemType })
+			}
+		}
+	default:
+		if providers := sr.providersOf(serviceType); len(providers) > 0 {
+			// The last registration by type is the one the container resolves
+			find(providers[len(providers)-1], func(service ServiceConfig) bool {
+				return service.Type == serviceType && service.Group == ""
+			})
+		}
+	}
+	return refs
+}
+
+// cycles reports every cycle of services that need each other to be created or started, once each.
+func (sr *ServiceRegistry) cycles() []ValidationProblem {
+	edges := make(map[string][]string, len(sr.services))
+	names := make([]string, 0, len(sr.services))
+	for name, serviceDef := range sr.services {
+		edges[name] = sr.creationDependencies(serviceDef)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	reported := make(map[string]bool)
+	var stack []string
+	var problems []ValidationProblem
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range edges[name] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				// The stack from dep onwards is a cycle; report it starting from its smallest name
+				var cycle []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == dep {
+						cycle = append([]string{}, stack[i:]...)
+						break
+					}
+				}
+				start := 0
+				for i, member := range cycle {
+					if member < cycle[start] {
+						start = i
+					}
+				}
+				cycle = append(cycle[start:], cycle[:start]...)
+				key := strings.Join(cycle, "\x00")
+				if !reported[key] {
+					reported[key] = true
+					problems = append(problems, ValidationProblem{
+						Kind:    ProblemCycle,
+						Service: cycle[0],
+						Message: fmt.Sprintf("dependency cycle %s -> %s", strings.Join(cycle, " -> "), cycle[0]),
+					})
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,255 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type wiringConfig struct{}
+
+type wiringConsumer struct{ config *wiringConfig }
+
+type wiringRequest struct{}
+
+type wiringServer struct{}
+
+func (s *wiringServer) Start(ctx context.Context) error { return nil }
+func (s *wiringServer) Stop(ctx context.Context) error  { return nil }
+
+type wiringA struct{ b *wiringB }
+
+type wiringB struct{ a *wiringA }
+
+func newWiringConfig() *wiringConfig { return &wiringConfig{} }
+
+func newWiringConsumer(config *wiringConfig) *wiringConsumer { return &wiringConsumer{config: config} }
+
+func newWiringServer() *wiringServer { return &wiringServer{} }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(sr *ServiceRegistry)
+		want     ProblemKind // Empty for a valid registry
+	}{
+		{
+			name: "valid",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringConfig](newWiringConfig, Singleton))
+				sr.Register(NewStructFactory[*wiringConsumer](newWiringConsumer, Singleton))
+				sr.Register(NewAutoServiceFactory[*wiringServer](newWiringServer, Singleton))
+			},
+		},
+		{
+			name: "missing_binding",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringConsumer](newWiringConsumer, Singleton))
+			},
+			want: ProblemMissingBinding,
+		},
+		{
+			name: "missing_dependency",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewAutoServiceFactory[*wiringServer](newWiringServer, Singleton).WithDependencies("unregistered"))
+			},
+			want: ProblemMissingDependency,
+		},
+		{
+			name: "ambiguous_binding",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringConfig](newWiringConfig, Singleton).WithName("first-config"))
+				sr.Register(NewStructFactory[*wiringConfig](newWiringConfig, Singleton).WithName("second-config"))
+				sr.Register(NewStructFactory[*wiringConsumer](newWiringConsumer, Singleton))
+			},
+			want: ProblemAmbiguousBinding,
+		},
+		{
+			name: "cycle",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringA](func(b *wiringB) *wiringA { return &wiringA{b: b} }, Singleton))
+				sr.Register(NewStructFactory[*wiringB](func(a *wiringA) *wiringB { return &wiringB{a: a} }, Singleton))
+			},
+			want: ProblemCycle,
+		},
+		{
+			name: "duplicate_name",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringConfig](newWiringConfig, Singleton).AsNamed("config").WithName("first-config"))
+				sr.Register(NewStructFactory[*wiringConfig](newWiringConfig, Singleton).AsNamed("config").WithName("second-config"))
+			},
+			want: ProblemDuplicateName,
+		},
+		{
+			name: "captive_dependency",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringRequest](func() *wiringRequest { return &wiringRequest{} }, Scoped))
+				sr.Register(NewStructFactory[*wiringConsumer](func(request *wiringRequest) *wiringConsumer {
+					return &wiringConsumer{}
+				}, Singleton))
+			},
+			want: ProblemCaptiveDependency,
+		},
+		{
+			name: "managed_lifetime",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewAutoServiceFactory[*wiringServer](newWiringServer, Transient))
+			},
+			want: ProblemManagedLifetime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.HealthCheckInterval = 0
+			sr := NewWithConfig(config)
+			tt.register(sr)
+
+			err := sr.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			if len(validationErr.Problems) == 0 {
+				t.Fatal("Validate() returned a *ValidationError without problems")
+			}
+			for _, problem := range validationErr.Problems {
+				if problem.Kind != tt.want {
+					t.Errorf("Validate() reported %s, want only %s problems", problem, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// registerCaptive registers a singleton consumer taking a request with the given lifetime
+func registerCaptive(sr *ServiceRegistry, lifetime Lifetime) {
+	sr.Register(NewStructFactory[*wiringRequest](func() *wiringRequest { return &wiringRequest{} }, lifetime))
+	sr.Register(NewStructFactory[*wiringConsumer](func(request *wiringRequest) *wiringConsumer {
+		return &wiringConsumer{}
+	}, Singleton))
+}
+
+func TestCaptiveDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(sr *ServiceRegistry)
+		captive  bool
+	}{
+		{
+			name:     "singleton_captures_transient",
+			register: func(sr *ServiceRegistry) { registerCaptive(sr, Transient) },
+			captive:  true,
+		},
+		{
+			name:     "singleton_of_singleton",
+			register: func(sr *ServiceRegistry) { registerCaptive(sr, Singleton) },
+		},
+		{
+			name: "scoped_of_transient",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringRequest](func() *wiringRequest { return &wiringRequest{} }, Transient))
+				sr.Register(NewStructFactory[*wiringConsumer](func(request *wiringRequest) *wiringConsumer {
+					return &wiringConsumer{}
+				}, Scoped))
+			},
+		},
+		{
+			name: "singleton_of_provider",
+			register: func(sr *ServiceRegistry) {
+				sr.Register(NewStructFactory[*wiringRequest](func() *wiringRequest { return &wiringRequest{} }, Scoped))
+				sr.Register(NewStructFactory[*wiringConsumer](func(request func() (*wiringRequest, error)) *wiringConsumer {
+					return &wiringConsumer{}
+				}, Singleton))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := newTestRegistry()
+			tt.register(sr)
+
+			err := sr.Validate()
+			if !tt.captive {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 {
+				t.Fatalf("Validate() = %v, want one captive dependency", err)
+			}
+			if problem := validationErr.Problems[0]; problem.Kind != ProblemCaptiveDependency || problem.Service != "*orchestrator::wiringConsumer" {
+				t.Errorf("Validate() reported %s, want the consumer capturing the request", problem)
+			}
+		})
+	}
+}
+
+func TestCaptiveDependencyStrictness(t *testing.T) {
+	newRegistry := func(strictness Strictness) *ServiceRegistry {
+		config := DefaultConfig()
+		config.HealthCheckInterval = 0
+		config.CaptiveDependencies = strictness
+		return NewWithConfig(config)
+	}
+
+	if DefaultConfig().CaptiveDependencies != StrictnessWarn {
+		t.Error("captive dependencies are not warned about by default")
+	}
+
+	t.Run("ignore", func(t *testing.T) {
+		sr := newRegistry(StrictnessIgnore)
+		registerCaptive(sr, Scoped)
+		if err := sr.Validate(); err != nil {
+			t.Errorf("Validate() = %v, want captive dependencies ignored", err)
+		}
+		startRegistry(t, sr)
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		sr := newRegistry(StrictnessWarn)
+		registerCaptive(sr, Scoped)
+		if err := sr.Validate(); err == nil {
+			t.Error("Validate() = nil, want the captive dependency reported")
+		}
+		startRegistry(t, sr)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sr := newRegistry(StrictnessError)
+		registerCaptive(sr, Scoped)
+		err := sr.Start(context.Background())
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Problems[0].Kind != ProblemCaptiveDependency {
+			t.Fatalf("Start() = %v, want the captive dependency", err)
+		}
+	})
+
+	t.Run("error_on_add", func(t *testing.T) {
+		sr := newRegistry(StrictnessError)
+		sr.Register(NewStructFactory[*wiringRequest](func() *wiringRequest { return &wiringRequest{} }, Scoped))
+		startRegistry(t, sr)
+
+		err := sr.Add(context.Background(), NewStructFactory[*wiringConsumer](func(request *wiringRequest) *wiringConsumer {
+			return &wiringConsumer{}
+		}, Singleton))
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("Add() = %v, want the captive dependency", err)
+		}
+		if _, err := ResolveStruct[*wiringConsumer](sr.Container()); err == nil {
+			t.Error("rejected service is resolvable")
+		}
+	})
+}
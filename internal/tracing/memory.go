@@ -0,0 +1,199 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// SpanData is a finished span as handed to an exporter
+type SpanData struct {
+	Name              string
+	TraceID           string
+	SpanID            string
+	ParentSpanID      string // Empty for root spans
+	StartTime         time.Time
+	EndTime           time.Time
+	Attributes        map[string]interface{}
+	Events            []Event
+	Status            StatusCode
+	StatusDescription string
+}
+
+// Duration returns how long the span took
+func (s SpanData) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// Event is something that happened during a span
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// Exporter receives finished spans
+type Exporter interface {
+	// ExportSpan exports a finished span. It must be safe for concurrent use.
+	ExportSpan(span SpanData)
+}
+
+// NewTracer creates a tracer that records spans and hands them to the exporter when they end
+func NewTracer(exporter Exporter) Tracer {
+	return &recordingTracer{exporter: exporter}
+}
+
+type recordingTracer struct {
+	exporter Exporter
+}
+
+func (t *recordingTracer) Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordingSpan{
+		exporter: t.exporter,
+		data: SpanData{
+			Name:       spanName,
+			SpanID:     newID(8),
+			StartTime:  time.Now(),
+			Attributes: make(map[string]interface{}, len(attrs)),
+		},
+	}
+
+	if parent := SpanFromContext(ctx).SpanContext(); parent.IsValid() {
+		span.data.TraceID = parent.TraceID
+		span.data.ParentSpanID = parent.SpanID
+	} else {
+		span.data.TraceID = newID(16)
+	}
+
+	span.SetAttributes(attrs...)
+
+	return ContextWithSpan(ctx, span), span
+}
+
+type recordingSpan struct {
+	exporter Exporter
+	data     SpanData
+	ended    bool
+	mu       sync.Mutex
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.exporter != nil {
+		s.exporter.ExportSpan(data)
+	}
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	// Follow the OpenTelemetry semantic conventions for exceptions
+	s.data.Events = append(s.data.Events, Event{
+		Name: "exception",
+		Time: time.Now(),
+		Attributes: map[string]interface{}{
+			"exception.message": err.Error(),
+		},
+	})
+}
+
+func (s *recordingSpan) SetStatus(code StatusCode, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.data.Status = code
+	if code == StatusError {
+		s.data.StatusDescription = description
+	} else {
+		s.data.StatusDescription = ""
+	}
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID}
+}
+
+func (s *recordingSpan) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.ended
+}
+
+// InMemoryExporter keeps finished spans in memory, for tests and offline inspection
+type InMemoryExporter struct {
+	spans []SpanData
+	mu    sync.Mutex
+}
+
+// NewInMemoryExporter creates a new in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan stores a finished span
+func (e *InMemoryExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the finished spans in the order they ended
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spans := make([]SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset discards all stored spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
+
+// newID returns a random hex-encoded identifier of the given number of bytes
+func newID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
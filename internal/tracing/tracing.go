@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+)
+
+// Tracer creates spans. Its shape follows OpenTelemetry's trace.Tracer, so an adapter
+// around an OpenTelemetry tracer only needs to convert attributes and status codes.
+type Tracer interface {
+	// Start creates a span as a child of the span in ctx, if any, and returns a context containing it
+	Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced operation, following OpenTelemetry span semantics
+type Span interface {
+	// End completes the span
+	End()
+
+	// SetAttributes sets attributes on the span, overwriting attributes with the same key
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError records an error as a span event. It does not change the span status.
+	RecordError(err error)
+
+	// SetStatus sets the status of the span
+	SetStatus(code StatusCode, description string)
+
+	// SpanContext returns the identifiers of the span
+	SpanContext() SpanContext
+
+	// IsRecording reports whether the span records information
+	IsRecording() bool
+}
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// IsValid reports whether the span context identifies a span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+// StatusCode is the status of a span
+type StatusCode int
+
+const (
+	// StatusUnset is the default status
+	StatusUnset StatusCode = iota
+	// StatusError indicates the operation failed
+	StatusError
+	// StatusOK indicates the operation completed successfully
+	StatusOK
+)
+
+// String returns the string representation of the status code
+func (c StatusCode) String() string {
+	switch c {
+	case StatusError:
+		return "Error"
+	case StatusOK:
+		return "Ok"
+	default:
+		return "Unset"
+	}
+}
+
+// Attribute is a key/value pair describing a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an integer attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Int64 creates an integer attribute
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool creates a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Float64 creates a floating point attribute
+func Float64(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Strings creates a string slice attribute
+func Strings(key string, value []string) Attribute {
+	return Attribute{Key: key, Value: append([]string{}, value...)}
+}
+
+// End ends a span, recording err and setting the span status accordingly
+func End(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(StatusError, err.Error())
+	} else {
+		span.SetStatus(StatusOK, "")
+	}
+	span.End()
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a context containing the span
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, or a no-op span if there is none
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// NoopTracer returns a tracer that creates spans which record nothing
+func NoopTracer() Tracer {
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End()                                          {}
+func (noopSpan) SetAttributes(attrs ...Attribute)              {}
+func (noopSpan) RecordError(err error)                         {}
+func (noopSpan) SetStatus(code StatusCode, description string) {}
+func (noopSpan) SpanContext() SpanContext                      { return SpanContext{} }
+func (noopSpan) IsRecording() bool                             { return false }
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTracerLinksChildSpansToTheirParent(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	childCtx, child := tracer.Start(ctx, "child")
+	_, grandchild := tracer.Start(childCtx, "grandchild")
+	_, sibling := tracer.Start(ctx, "sibling")
+	grandchild.End()
+	child.End()
+	sibling.End()
+	root.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want 4", len(spans))
+	}
+
+	byName := make(map[string]SpanData)
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	if byName["root"].ParentSpanID != "" {
+		t.Errorf("root span has parent %q, want none", byName["root"].ParentSpanID)
+	}
+	parents := map[string]string{
+		"child":      "root",
+		"grandchild": "child",
+		"sibling":    "root",
+	}
+	for name, parent := range parents {
+		if got, want := byName[name].ParentSpanID, byName[parent].SpanID; got != want {
+			t.Errorf("%s span has parent %q, want %s span %q", name, got, parent, want)
+		}
+		if got, want := byName[name].TraceID, byName["root"].TraceID; got != want {
+			t.Errorf("%s span has trace %q, want the root trace %q", name, got, want)
+		}
+	}
+
+	// Spans end children first, and the exporter keeps them in that order
+	order := []string{"grandchild", "child", "sibling", "root"}
+	for i, span := range spans {
+		if span.Name != order[i] {
+			t.Errorf("span %d is %s, want %s", i, span.Name, order[i])
+		}
+	}
+}
+
+func TestTracerStartsANewTraceWithoutParent(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	_, first := tracer.Start(context.Background(), "first")
+	_, second := tracer.Start(context.Background(), "second")
+	first.End()
+	second.End()
+
+	spans := exporter.Spans()
+	if spans[0].TraceID == spans[1].TraceID {
+		t.Errorf("unrelated spans share trace %q", spans[0].TraceID)
+	}
+	for _, span := range spans {
+		if span.TraceID == "" || span.ParentSpanID != "" {
+			t.Errorf("%s span has trace %q and parent %q, want a root span of its own trace", span.Name, span.TraceID, span.ParentSpanID)
+		}
+	}
+}
+
+func TestEndSetsTheStatusFromTheError(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	End(ok, nil)
+	_, failed := tracer.Start(context.Background(), "failed")
+	End(failed, errors.New("connection refused"))
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	if spans[0].Status != StatusOK || spans[0].StatusDescription != "" || len(spans[0].Events) != 0 {
+		t.Errorf("ok span has status %s %q and %d events, want Ok without events",
+			spans[0].Status, spans[0].StatusDescription, len(spans[0].Events))
+	}
+
+	if spans[1].Status != StatusError || spans[1].StatusDescription != "connection refused" {
+		t.Errorf("failed span has status %s %q, want Error %q", spans[1].Status, spans[1].StatusDescription, "connection refused")
+	}
+	if len(spans[1].Events) != 1 || spans[1].Events[0].Name != "exception" ||
+		spans[1].Events[0].Attributes["exception.message"] != "connection refused" {
+		t.Errorf("failed span has events %+v, want one exception event", spans[1].Events)
+	}
+}
+
+func TestEndedSpansIgnoreChanges(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	_, span := tracer.Start(context.Background(), "span", String("key", "before"))
+	span.End()
+	span.SetAttributes(String("key", "after"))
+	span.SetStatus(StatusError, "too late")
+	span.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want the span exported once", len(spans))
+	}
+	if spans[0].Attributes["key"] != "before" || spans[0].Status != StatusUnset {
+		t.Errorf("ended span changed to %v with status %s", spans[0].Attributes, spans[0].Status)
+	}
+	if span.IsRecording() {
+		t.Error("ended span is still recording")
+	}
+}
+
+func TestNoopTracerRecordsNothing(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, span := NoopTracer().Start(ctx, "span")
+
+	if spanCtx != ctx {
+		t.Error("no-op tracer changed the context")
+	}
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Error("no-op span records")
+	}
+	if SpanFromContext(ctx).IsRecording() {
+		t.Error("context without span returned a recording span")
+	}
+}
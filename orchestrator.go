@@ -50,6 +50,9 @@ type (
 	// HealthStatus represents the health status of a component.
 	HealthStatus = orchestrator.HealthStatus
 
+	// HealthTransition describes a change in a service's health status observed by a health check.
+	HealthTransition = orchestrator.HealthTransition
+
 	// Service represents a service that can be managed by the orchestrator.
 	// All services MUST implement this interface for automatic lifecycle management.
 	Service = orchestrator.Service
@@ -59,6 +62,110 @@ type (
 	// all methods manually.
 	BaseService = orchestrator.BaseService
 
+	// RetryConfig configures retry behavior for service lifecycle operations.
+	RetryConfig = orchestrator.RetryConfig
+
+	// RestartPolicy determines when a failed service is restarted automatically.
+	RestartPolicy = orchestrator.RestartPolicy
+
+	// RestartConfig configures automatic restarts of a failed service.
+	RestartConfig = orchestrator.RestartConfig
+
+	// RestartRecord records a restart of a service.
+	RestartRecord = orchestrator.RestartRecord
+
+	// TimeoutError is returned when a service's Start or Stop call overruns its timeout.
+	TimeoutError = orchestrator.TimeoutError
+
+	// LifecycleError reports every service that failed during startup or shutdown.
+	// It supports errors.Is and errors.As over all service errors, like errors.Join.
+	LifecycleError = orchestrator.LifecycleError
+
+	// RollbackReport describes the services stopped while rolling back a failed startup.
+	RollbackReport = orchestrator.RollbackReport
+
+	// RollbackEntry describes the rollback of a single service.
+	RollbackEntry = orchestrator.RollbackEntry
+
+	// StartupReport is the timing profile of a startup, exportable as JSON.
+	StartupReport = orchestrator.StartupReport
+
+	// LevelTiming is the timing of a single dependency level during startup.
+	LevelTiming = orchestrator.LevelTiming
+
+	// ComponentTiming is the startup timing of a single service.
+	ComponentTiming = orchestrator.ComponentTiming
+
+	// CriticalPath is the longest chain of dependent services by start duration.
+	CriticalPath = orchestrator.CriticalPath
+
+	// MetricsProvider receives dependency injection metrics.
+	MetricsProvider = orchestrator.MetricsProvider
+
+	// LifecycleMetricsRecorder receives service lifecycle metrics.
+	LifecycleMetricsRecorder = orchestrator.LifecycleMetricsRecorder
+
+	// MetricsCollector is the built-in in-memory metrics backend.
+	// It can serve its metrics in the Prometheus text exposition format.
+	MetricsCollector = orchestrator.MetricsCollector
+
+	// Tracer creates spans for startup, shutdown and resolution. Its shape follows
+	// OpenTelemetry's tracer, so an adapter only needs to convert attributes and status codes.
+	Tracer = orchestrator.Tracer
+
+	// Span is a single traced operation.
+	Span = orchestrator.Span
+
+	// SpanContext identifies a span within a trace.
+	SpanContext = orchestrator.SpanContext
+
+	// SpanAttribute is a key/value pair describing a span.
+	SpanAttribute = orchestrator.SpanAttribute
+
+	// SpanStatusCode is the status of a span.
+	SpanStatusCode = orchestrator.SpanStatusCode
+
+	// SpanData is a finished span as handed to an exporter.
+	SpanData = orchestrator.SpanData
+
+	// SpanEvent is something that happened during a span, such as a recorded error.
+	SpanEvent = orchestrator.SpanEvent
+
+	// SpanExporter receives finished spans.
+	SpanExporter = orchestrator.SpanExporter
+
+	// InMemorySpanExporter keeps finished spans in memory, for tests and offline inspection.
+	InMemorySpanExporter = orchestrator.InMemorySpanExporter
+
+	// Interceptor intercepts the creation of service instances. Registry-wide interceptors wrap
+	// the interceptors and decorators of a service; within each group the one added first is outermost.
+	Interceptor = orchestrator.Interceptor
+
+	// InterceptorFunc is a function adapter for Interceptor.
+	InterceptorFunc = orchestrator.InterceptorFunc
+
+	// Graph is a serializable snapshot of the service dependency graph.
+	// It can be exported to Graphviz DOT, Mermaid and JSON.
+	Graph = orchestrator.Graph
+
+	// GraphNode describes a single service in the dependency graph.
+	GraphNode = orchestrator.GraphNode
+
+	// RemoveOption configures how ServiceRegistry.Remove handles dependent services.
+	RemoveOption = orchestrator.RemoveOption
+
+	// ValidationError reports every problem found by ServiceRegistry.Validate.
+	ValidationError = orchestrator.ValidationError
+
+	// ValidationProblem is a single problem found by ServiceRegistry.Validate.
+	ValidationProblem = orchestrator.ValidationProblem
+
+	// ProblemKind classifies a problem found by ServiceRegistry.Validate.
+	ProblemKind = orchestrator.ProblemKind
+
+	// Strictness determines how the registry handles a wiring problem it detects.
+	Strictness = orchestrator.Strictness
+
 	// Container provides a simplified interface to the DI container.
 	Container = orchestrator.Container
 
@@ -91,6 +198,50 @@ const (
 	HealthStatusUnknown HealthStatusType = orchestrator.HealthStatusUnknown
 )
 
+const (
+	// RestartNever never restarts the service automatically
+	RestartNever RestartPolicy = orchestrator.RestartNever
+	// RestartOnFailure restarts the service when it reports unhealthy or fails to start
+	RestartOnFailure RestartPolicy = orchestrator.RestartOnFailure
+	// RestartAlways restarts the service whenever it is not running while the registry is running
+	RestartAlways RestartPolicy = orchestrator.RestartAlways
+)
+
+const (
+	// SpanStatusUnset is the default span status
+	SpanStatusUnset SpanStatusCode = orchestrator.SpanStatusUnset
+	// SpanStatusError indicates the traced operation failed
+	SpanStatusError SpanStatusCode = orchestrator.SpanStatusError
+	// SpanStatusOK indicates the traced operation succeeded
+	SpanStatusOK SpanStatusCode = orchestrator.SpanStatusOK
+)
+
+const (
+	// ProblemMissingBinding is a factory parameter or injected field that no service is registered for
+	ProblemMissingBinding ProblemKind = orchestrator.ProblemMissingBinding
+	// ProblemMissingDependency is a lifecycle dependency that is not a managed service, which fails startup
+	ProblemMissingDependency ProblemKind = orchestrator.ProblemMissingDependency
+	// ProblemAmbiguousBinding is a factory parameter or injected field that several services are registered for
+	ProblemAmbiguousBinding ProblemKind = orchestrator.ProblemAmbiguousBinding
+	// ProblemCycle is a cycle of services that need each other to be created
+	ProblemCycle ProblemKind = orchestrator.ProblemCycle
+	// ProblemDuplicateName is a service name registered by several services
+	ProblemDuplicateName ProblemKind = orchestrator.ProblemDuplicateName
+	// ProblemCaptiveDependency is a singleton service that captures a scoped or transient service
+	ProblemCaptiveDependency ProblemKind = orchestrator.ProblemCaptiveDependency
+	// ProblemManagedLifetime is a lifecycle-managed service that is not a singleton, which fails startup
+	ProblemManagedLifetime ProblemKind = orchestrator.ProblemManagedLifetime
+)
+
+const (
+	// StrictnessIgnore ignores the problem
+	StrictnessIgnore Strictness = orchestrator.StrictnessIgnore
+	// StrictnessWarn logs a warning and carries on
+	StrictnessWarn Strictness = orchestrator.StrictnessWarn
+	// StrictnessError fails with a *ValidationError
+	StrictnessError Strictness = orchestrator.StrictnessError
+)
+
 // Public API functions - delegate to internal implementation
 
 // DefaultConfig returns the default application configuration.
@@ -98,6 +249,42 @@ func DefaultConfig() Config {
 	return orchestrator.DefaultConfig()
 }
 
+// DefaultRetryConfig returns the default retry configuration.
+func DefaultRetryConfig() RetryConfig {
+	return orchestrator.DefaultRetryConfig()
+}
+
+// DefaultRestartConfig returns the default restart configuration for the given policy.
+func DefaultRestartConfig(policy RestartPolicy) RestartConfig {
+	return orchestrator.DefaultRestartConfig(policy)
+}
+
+// NewMetricsCollector creates an in-memory metrics collector. If no latency histogram
+// buckets (in seconds) are given, default buckets are used.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	return orchestrator.NewMetricsCollector(buckets...)
+}
+
+// NewTracer creates a tracer that records spans and hands them to the exporter when they end.
+func NewTracer(exporter SpanExporter) Tracer {
+	return orchestrator.NewTracer(exporter)
+}
+
+// NewInMemorySpanExporter creates an exporter that keeps finished spans in memory.
+func NewInMemorySpanExporter() *InMemorySpanExporter {
+	return orchestrator.NewInMemorySpanExporter()
+}
+
+// NoopTracer returns a tracer that records nothing.
+func NoopTracer() Tracer {
+	return orchestrator.NoopTracer()
+}
+
+// WithCascade makes ServiceRegistry.Remove also remove the services that depend on the removed service.
+func WithCascade() RemoveOption {
+	return orchestrator.WithCascade()
+}
+
 // New creates a new application with the default configuration.
 func New() *ServiceRegistry {
 	return orchestrator.New()
@@ -124,6 +311,10 @@ func NewServiceSingleton[T Service](instance T) *orchestrator.TypedServiceDefini
 // The factory function can return any type T - it doesn't need to implement the Service interface.
 // Dependencies are automatically discovered from the factory function parameters.
 // Lifecycle methods are automatically provided with sensible defaults.
+// The factory function returns T, (T, error) or (T, func(), error). A returned cleanup function is called
+// when the owner of the instance is torn down, in reverse creation order.
+// The lifetime must be Singleton, since Stop and Health act on the instance that was started;
+// Start and Add fail for other lifetimes.
 func NewAutoServiceFactory[T any](factory interface{}, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
 	return orchestrator.NewAutoServiceFactory[T](factory, lifetime)
 }
@@ -132,6 +323,10 @@ func NewAutoServiceFactory[T any](factory interface{}, lifetime Lifetime) *orche
 // The factory function must return a type T that implements the Service interface.
 // Dependencies are automatically discovered from the factory function parameters.
 // Lifecycle methods (Start, Stop, Health) are automatically wired.
+// The factory function returns T, (T, error) or (T, func(), error). A returned cleanup function is called
+// when the owner of the instance is torn down, in reverse creation order.
+// The lifetime must be Singleton, since Stop and Health act on the instance that was started;
+// Start and Add fail for other lifetimes.
 func NewServiceFactory[T Service](factory interface{}, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
 	return orchestrator.NewServiceFactory[T](factory, lifetime)
 }
@@ -147,10 +342,129 @@ func NewStructSingleton[T any](instance T) *orchestrator.TypedServiceDefinition[
 // The factory function can return any struct type T.
 // Dependencies are automatically discovered from the factory function parameters.
 // No lifecycle management is provided - structs are registered as-is.
+// The factory function returns T, (T, error) or (T, func(), error). A returned cleanup function is called
+// when the owner of the instance is torn down, in reverse creation order.
 func NewStructFactory[T any](factory interface{}, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
 	return orchestrator.NewStructFactory[T](factory, lifetime)
 }
 
+// NewStructInjected creates a new service definition for a struct whose dependencies are injected into
+// fields tagged `inject:""`, `inject:"name"` or `inject:",optional"`.
+// Dependencies are automatically discovered from the tagged fields.
+func NewStructInjected[T any]() *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.NewStructInjected[T]()
+}
+
+// Provide0 is Provide1 for factories without dependencies.
+func Provide0[T any](factory func() (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide0[T](factory, lifetime)
+}
+
+// Provide0NoError is Provide0 for factories that cannot fail.
+func Provide0NoError[T any](factory func() T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide0NoError[T](factory, lifetime)
+}
+
+// Provide0WithCleanup is Provide1WithCleanup for factories without dependencies.
+func Provide0WithCleanup[T any](factory func() (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide0WithCleanup[T](factory, lifetime)
+}
+
+// Provide1 creates a service definition from a factory taking one dependency, such as a constructor.
+// Unlike NewStructFactory, the factory signature is checked at compile time and the factory is called
+// without reflection. Dependencies are resolved and discovered from the parameter types as usual.
+// If T has a Start method, it is called when the service starts.
+func Provide1[A, T any](factory func(A) (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide1[A, T](factory, lifetime)
+}
+
+// Provide1NoError is Provide1 for factories that cannot fail.
+func Provide1NoError[A, T any](factory func(A) T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide1NoError[A, T](factory, lifetime)
+}
+
+// Provide1WithCleanup is Provide1 for factories that also return a cleanup function, as Wire providers do.
+// The cleanup function is called in reverse creation order when the registry stops or, for instances
+// created in a scope, when the scope is disposed.
+func Provide1WithCleanup[A, T any](factory func(A) (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide1WithCleanup[A, T](factory, lifetime)
+}
+
+// Provide2 is Provide1 for factories taking 2 dependencies.
+func Provide2[A, B, T any](factory func(A, B) (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide2[A, B, T](factory, lifetime)
+}
+
+// Provide2NoError is Provide2 for factories that cannot fail.
+func Provide2NoError[A, B, T any](factory func(A, B) T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide2NoError[A, B, T](factory, lifetime)
+}
+
+// Provide2WithCleanup is Provide1WithCleanup for factories taking 2 dependencies.
+func Provide2WithCleanup[A, B, T any](factory func(A, B) (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide2WithCleanup[A, B, T](factory, lifetime)
+}
+
+// Provide3 is Provide1 for factories taking 3 dependencies.
+func Provide3[A, B, C, T any](factory func(A, B, C) (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide3[A, B, C, T](factory, lifetime)
+}
+
+// Provide3NoError is Provide3 for factories that cannot fail.
+func Provide3NoError[A, B, C, T any](factory func(A, B, C) T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide3NoError[A, B, C, T](factory, lifetime)
+}
+
+// Provide3WithCleanup is Provide1WithCleanup for factories taking 3 dependencies.
+func Provide3WithCleanup[A, B, C, T any](factory func(A, B, C) (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide3WithCleanup[A, B, C, T](factory, lifetime)
+}
+
+// Provide4 is Provide1 for factories taking 4 dependencies.
+func Provide4[A, B, C, D, T any](factory func(A, B, C, D) (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide4[A, B, C, D, T](factory, lifetime)
+}
+
+// Provide4NoError is Provide4 for factories that cannot fail.
+func Provide4NoError[A, B, C, D, T any](factory func(A, B, C, D) T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide4NoError[A, B, C, D, T](factory, lifetime)
+}
+
+// Provide4WithCleanup is Provide1WithCleanup for factories taking 4 dependencies.
+func Provide4WithCleanup[A, B, C, D, T any](factory func(A, B, C, D) (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide4WithCleanup[A, B, C, D, T](factory, lifetime)
+}
+
+// Provide5 is Provide1 for factories taking 5 dependencies.
+func Provide5[A, B, C, D, E, T any](factory func(A, B, C, D, E) (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide5[A, B, C, D, E, T](factory, lifetime)
+}
+
+// Provide5NoError is Provide5 for factories that cannot fail.
+func Provide5NoError[A, B, C, D, E, T any](factory func(A, B, C, D, E) T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide5NoError[A, B, C, D, E, T](factory, lifetime)
+}
+
+// Provide5WithCleanup is Provide1WithCleanup for factories taking 5 dependencies.
+func Provide5WithCleanup[A, B, C, D, E, T any](factory func(A, B, C, D, E) (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide5WithCleanup[A, B, C, D, E, T](factory, lifetime)
+}
+
+// Provide6 is Provide1 for factories taking 6 dependencies.
+func Provide6[A, B, C, D, E, F, T any](factory func(A, B, C, D, E, F) (T, error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide6[A, B, C, D, E, F, T](factory, lifetime)
+}
+
+// Provide6NoError is Provide6 for factories that cannot fail.
+func Provide6NoError[A, B, C, D, E, F, T any](factory func(A, B, C, D, E, F) T, lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide6NoError[A, B, C, D, E, F, T](factory, lifetime)
+}
+
+// Provide6WithCleanup is Provide1WithCleanup for factories taking 6 dependencies.
+func Provide6WithCleanup[A, B, C, D, E, F, T any](factory func(A, B, C, D, E, F) (T, func(), error), lifetime Lifetime) *orchestrator.TypedServiceDefinition[T] {
+	return orchestrator.Provide6WithCleanup[A, B, C, D, E, F, T](factory, lifetime)
+}
+
 // ResolveType resolves a service by interface type.
 // T must be an interface type, not a concrete struct.
 func ResolveType[T any](c *Container) (T, error) {
@@ -163,6 +477,41 @@ func ResolveStruct[T any](c *Container) (T, error) {
 	return orchestrator.ResolveStruct[T](c)
 }
 
+// ResolveTypeCtx resolves a service by interface type from the container in ctx, such as the
+// request scope opened by ServiceRegistry.ScopeMiddleware.
+// T must be an interface type, not a concrete struct.
+func ResolveTypeCtx[T any](ctx context.Context) (T, error) {
+	return orchestrator.ResolveTypeCtx[T](ctx)
+}
+
+// ResolveStructCtx resolves a service by struct type from the container in ctx.
+// T can be any type (interface or struct).
+func ResolveStructCtx[T any](ctx context.Context) (T, error) {
+	return orchestrator.ResolveStructCtx[T](ctx)
+}
+
+// ContextWithContainer returns a context carrying the container, to be resolved from with ResolveTypeCtx.
+func ContextWithContainer(ctx context.Context, container *Container) context.Context {
+	return orchestrator.ContextWithContainer(ctx, container)
+}
+
+// ContainerFromContext returns the container in ctx, or nil if there is none.
+func ContainerFromContext(ctx context.Context) *Container {
+	return orchestrator.ContainerFromContext(ctx)
+}
+
+// ResolveAll resolves every service of type T, including the members of every group.
+// Services are ordered by priority, highest first, and then by registration order.
+func ResolveAll[T any](c *Container) ([]T, error) {
+	return orchestrator.ResolveAll[T](c)
+}
+
+// ResolveGroup resolves the services of type T in a group, ordered by priority, highest first,
+// and then by registration order.
+func ResolveGroup[T any](c *Container, group string) ([]T, error) {
+	return orchestrator.ResolveGroup[T](c, group)
+}
+
 // RunWithGracefulShutdown provides a convenience function for running the orchestrator
 // with graceful shutdown handling. This is an optional utility - applications can
 // still implement their own signal handling and shutdown logic if needed.
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Optional is a factory parameter or injected field for a dependency that may not be registered.
+// If no matching service is registered, the container injects an absent Optional instead of failing,
+// and the dependency does not constrain the startup order.
+type Optional[T any] struct {
+	value   T
+	present bool
+}
+
+// Get returns the dependency and whether it is present.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.present
+}
+
+// Present reports whether the dependency is registered.
+func (o Optional[T]) Present() bool {
+	return o.present
+}
+
+// OrElse returns the dependency if it is present, and fallback otherwise.
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.present {
+		return o.value
+	}
+	return fallback
+}
+
+// DependencyType returns the type of the dependency. It is used by the container.
+func (Optional[T]) DependencyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// WithValue returns a present Optional holding the instance. It is used by the container.
+func (Optional[T]) WithValue(instance interface{}) interface{} {
+	value, _ := instance.(T)
+	return Optional[T]{value: value, present: true}
+}
+
+// Lazy is a factory parameter or injected field for a dependency that is only resolved when Get is
+// first called. It breaks cycles between services that need each other only after construction, and
+// avoids creating expensive services that are only used on some code paths. A lazy dependency does not
+// constrain the startup order, so it may not have been started when Get is called.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	resolve  func() (interface{}, error)
+	value    T
+	resolved bool
+	mu       sync.Mutex
+}
+
+// Get resolves the dependency on the first call and returns the same instance on later calls.
+// A failed resolution is retried on the next call.
+func (l Lazy[T]) Get() (T, error) {
+	var zero T
+	if l.state == nil {
+		return zero, fmt.Errorf("lazy dependency of type %s was not injected by the container", reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+
+	if l.state.resolved {
+		return l.state.value, nil
+	}
+
+	instance, err := l.state.resolve()
+	if err != nil {
+		return zero, err
+	}
+	value, ok := instance.(T)
+	if !ok && instance != nil {
+		return zero, fmt.Errorf("service of type %T is not a %s", instance, reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	l.state.value = value
+	l.state.resolved = true
+	return value, nil
+}
+
+// DependencyType returns the type of the dependency. It is used by the container.
+func (Lazy[T]) DependencyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// WithResolver returns a Lazy that resolves the dependency with resolve. It is used by the container.
+func (Lazy[T]) WithResolver(resolve func() (interface{}, error)) interface{} {
+	return Lazy[T]{state: &lazyState[T]{resolve: resolve}}
+}
@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type paramsCache struct{ name string }
+
+type paramsStore struct {
+	cache Optional[*paramsCache]
+}
+
+type paramsParent struct {
+	child Lazy[*paramsChild]
+}
+
+type paramsChild struct {
+	parent *paramsParent
+}
+
+// newParamsRegistry creates a registry without the background health monitor
+func newParamsRegistry() *ServiceRegistry {
+	config := DefaultConfig()
+	config.HealthCheckInterval = 0
+	return NewWithConfig(config)
+}
+
+// startParamsRegistry starts the registry and stops it when the test ends
+func startParamsRegistry(t *testing.T, registry *ServiceRegistry) {
+	t.Helper()
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(func() { _ = registry.Stop(context.Background()) })
+}
+
+func TestOptionalDependency(t *testing.T) {
+	for _, registered := range []bool{false, true} {
+		registry := newParamsRegistry()
+		registry.Register(NewStructFactory[*paramsStore](func(cache Optional[*paramsCache]) *paramsStore {
+			return &paramsStore{cache: cache}
+		}, Singleton))
+		if registered {
+			registry.Register(NewStructFactory[*paramsCache](func() *paramsCache { return &paramsCache{name: "redis"} }, Singleton))
+		}
+		startParamsRegistry(t, registry)
+
+		store, err := ResolveStruct[*paramsStore](registry.Container())
+		if err != nil {
+			t.Fatalf("ResolveStruct() = %v with the cache registered: %v", err, registered)
+		}
+		cache, present := store.cache.Get()
+		if present != registered || store.cache.Present() != registered {
+			t.Errorf("cache present = %v, want %v", present, registered)
+		}
+		want := "memory"
+		if registered {
+			want = "redis"
+		}
+		if got := store.cache.OrElse(&paramsCache{name: "memory"}).name; got != want {
+			t.Errorf("OrElse() = %s, want %s", got, want)
+		}
+		if registered && cache.name != "redis" {
+			t.Errorf("Get() = %+v, want the registered cache", cache)
+		}
+	}
+}
+
+func TestLazyDependencyBreaksCycle(t *testing.T) {
+	registry := newParamsRegistry()
+	registry.Register(NewStructFactory[*paramsParent](func(child Lazy[*paramsChild]) *paramsParent {
+		return &paramsParent{child: child}
+	}, Singleton))
+	registry.Register(NewStructFactory[*paramsChild](func(parent *paramsParent) *paramsChild {
+		return &paramsChild{parent: parent}
+	}, Singleton))
+	if err := registry.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want the lazy dependency not to count as a cycle", err)
+	}
+	startParamsRegistry(t, registry)
+
+	parent, err := ResolveStruct[*paramsParent](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	child, err := parent.child.Get()
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if child.parent != parent {
+		t.Error("child got another parent than the one holding it")
+	}
+	if again, _ := parent.child.Get(); again != child {
+		t.Error("second Get() returned another instance")
+	}
+}
+
+func TestLazyDependencyRetriesFailedResolution(t *testing.T) {
+	registry := newParamsRegistry()
+	registry.Register(NewStructFactory[*paramsParent](func(child Lazy[*paramsChild]) *paramsParent {
+		return &paramsParent{child: child}
+	}, Singleton))
+	calls := 0
+	registry.Register(NewStructFactory[*paramsChild](func() (*paramsChild, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("not ready")
+		}
+		return &paramsChild{}, nil
+	}, Singleton))
+	startParamsRegistry(t, registry)
+
+	parent, err := ResolveStruct[*paramsParent](registry.Container())
+	if err != nil {
+		t.Fatalf("ResolveStruct() = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("child was created %d times before Get", calls)
+	}
+	if _, err := parent.child.Get(); err == nil {
+		t.Fatal("first Get() succeeded, want the factory error")
+	}
+	if child, err := parent.child.Get(); err != nil || child == nil {
+		t.Errorf("second Get() = %v, %v, want the resolution retried", child, err)
+	}
+}
+
+func TestLazyZeroValue(t *testing.T) {
+	var lazy Lazy[*paramsChild]
+	if _, err := lazy.Get(); err == nil {
+		t.Error("Get() of a Lazy not injected by the container succeeded")
+	}
+}